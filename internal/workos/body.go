@@ -0,0 +1,42 @@
+package workos
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/workos/workos-go/v3/pkg/workos_errors"
+)
+
+// DefaultMaxResponseBytes is the maximum size of a response body when a client
+// does not configure one.
+const DefaultMaxResponseBytes = 32 << 20
+
+// LimitResponseBody limits the body of the response to max bytes, or to
+// DefaultMaxResponseBytes when max is zero or negative. Reading past the
+// limit fails with workos_errors.ErrResponseTooLarge.
+func LimitResponseBody(res *http.Response, max int64) {
+	if max <= 0 {
+		max = DefaultMaxResponseBytes
+	}
+	res.Body = &limitedBody{ReadCloser: res.Body, remaining: max}
+}
+
+// limitedBody is a response body that fails once more than remaining bytes
+// were read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, workos_errors.ErrResponseTooLarge
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), workos_errors.ErrResponseTooLarge
+	}
+	return n, err
+}
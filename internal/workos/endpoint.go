@@ -0,0 +1,26 @@
+package workos
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// ProductionEndpoint is the endpoint of the WorkOS API.
+	ProductionEndpoint = "https://api.workos.com"
+
+	// EndpointEnvVar is the environment variable overriding the endpoint used
+	// by clients whose Endpoint is not set, eg. to point a whole application
+	// at a sandbox without code changes.
+	EndpointEnvVar = "WORKOS_API_ENDPOINT"
+)
+
+// DefaultEndpoint returns the endpoint used by clients whose Endpoint is not
+// set: the value of the WORKOS_API_ENDPOINT environment variable when set, and
+// ProductionEndpoint otherwise.
+func DefaultEndpoint() string {
+	if endpoint := os.Getenv(EndpointEnvVar); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+	return ProductionEndpoint
+}
@@ -0,0 +1,130 @@
+package workos
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/workos/workos-go/v3/pkg/common"
+	"github.com/workos/workos-go/v3/pkg/workos_errors"
+)
+
+// Hooks are the settings of a client that Do applies to every request.
+type Hooks struct {
+	// The WorkOS API version the request is pinned to, if any.
+	APIVersion string
+
+	// Headers set on the request unless it already sets them. The headers set
+	// by the clients themselves, such as Authorization, are never overridden.
+	DefaultHeaders map[string]string
+
+	// Called with a copy of the request just before it is sent.
+	OnRequest func(*http.Request)
+
+	// Called once each attempt at sending the request completed.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of the response body, DefaultMaxResponseBytes when
+	// zero.
+	MaxResponseBytes int64
+}
+
+// reservedHeaders are the headers that DefaultHeaders can never set.
+var reservedHeaders = map[string]bool{
+	"Authorization":   true,
+	"Content-Type":    true,
+	"Idempotency-Key": true,
+	"User-Agent":      true,
+}
+
+// Do sends the request with client after applying the given hooks, and limits
+// the body of the response to hooks.MaxResponseBytes.
+func Do(client *http.Client, req *http.Request, hooks Hooks) (*http.Response, error) {
+	for k, v := range hooks.DefaultHeaders {
+		if reservedHeaders[http.CanonicalHeaderKey(k)] || req.Header.Get(k) != "" {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	if hooks.APIVersion != "" {
+		req.Header.Set(APIVersionHeader, hooks.APIVersion)
+	}
+
+	if hooks.OnRequest != nil {
+		hooks.OnRequest(inspectableRequest(req))
+	}
+
+	var reporter *attemptReporter
+	if hooks.OnRequestComplete != nil {
+		reporter = &attemptReporter{method: req.Method, path: req.URL.Path, hook: hooks.OnRequestComplete}
+		req = req.WithContext(context.WithValue(req.Context(), attemptReporterKey{}, reporter))
+	}
+
+	start := time.Now()
+	res, err := client.Do(req)
+
+	// The request is reported as a single attempt unless the transport
+	// reported its attempts itself.
+	if reporter != nil && !reporter.reported {
+		reporter.report(1, res, time.Since(start))
+	}
+	if err == nil {
+		LimitResponseBody(res, hooks.MaxResponseBytes)
+	}
+	return res, err
+}
+
+// TryGetHTTPError returns the error of a failed response, passed through
+// handler when it is not nil.
+func TryGetHTTPError(res *http.Response, handler common.ErrorHandler) error {
+	err := workos_errors.TryGetHTTPError(res)
+	if err != nil && handler != nil {
+		return handler(res, err)
+	}
+	return err
+}
+
+// inspectableRequest returns a copy of the given request whose body can be read
+// without consuming the body of the original request.
+func inspectableRequest(req *http.Request) *http.Request {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			r.Body = body
+		}
+	}
+	return r
+}
+
+type attemptReporterKey struct{}
+
+// attemptReporter reports the attempts at sending a request to the
+// OnRequestComplete hook of the client sending it.
+type attemptReporter struct {
+	method   string
+	path     string
+	hook     common.RequestCompleteFunc
+	reported bool
+}
+
+func (r *attemptReporter) report(attempt int, res *http.Response, duration time.Duration) {
+	r.reported = true
+
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	r.hook(r.method, r.path, attempt, status, duration)
+}
+
+// ReportAttempt reports an attempt at sending the request with the given
+// context, starting at 1, to the OnRequestComplete hook of the client sending
+// it. It is meant for transports retrying requests, so that each attempt is
+// reported rather than the request as a whole. res is nil when the attempt
+// failed without a response.
+func ReportAttempt(ctx context.Context, attempt int, res *http.Response, duration time.Duration) {
+	if reporter, ok := ctx.Value(attemptReporterKey{}).(*attemptReporter); ok {
+		reporter.report(attempt, res, duration)
+	}
+}
@@ -3,4 +3,8 @@ package workos
 const (
 	// Version represents the SDK version number.
 	Version = "v3.2.0"
+
+	// APIVersionHeader is the header pinning the WorkOS API version used to
+	// process a request.
+	APIVersionHeader = "WorkOS-Version"
 )
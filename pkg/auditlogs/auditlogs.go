@@ -35,14 +35,12 @@ package auditlogs
 
 import (
 	"context"
+	"io"
 )
 
 var (
 	// DefaultClient is the client used by SetAPIKey and Publish functions.
-	DefaultClient = &Client{
-		EventsEndpoint:  "https://api.workos.com/audit_logs/events",
-		ExportsEndpoint: "https://api.workos.com/audit_logs/exports",
-	}
+	DefaultClient = &Client{}
 )
 
 // SetAPIKey sets the WorkOS API key to use when using Publish.
@@ -64,3 +62,18 @@ func CreateExport(ctx context.Context, e CreateExportOpts) (AuditLogExport, erro
 func GetExport(ctx context.Context, e GetExportOpts) (AuditLogExport, error) {
 	return DefaultClient.GetExport(ctx, e)
 }
+
+// ListExports lists the Audit Log Exports matching the given options.
+func ListExports(ctx context.Context, opts ListExportsOpts) (ListExportsResponse, error) {
+	return DefaultClient.ListExports(ctx, opts)
+}
+
+// WaitForExport polls an Audit Log Export until it is ready and returns it.
+func WaitForExport(ctx context.Context, opts WaitForExportOpts) (AuditLogExport, error) {
+	return DefaultClient.WaitForExport(ctx, opts)
+}
+
+// DownloadExportTo streams the contents of a ready Audit Log Export to w.
+func DownloadExportTo(ctx context.Context, export AuditLogExport, w io.Writer) (int64, error) {
+	return DefaultClient.DownloadExportTo(ctx, export, w)
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
+	"github.com/google/go-querystring/query"
+	"github.com/workos/workos-go/v3/pkg/common"
 
 	"github.com/workos/workos-go/v3/internal/workos"
 )
@@ -36,19 +41,146 @@ type Client struct {
 	HTTPClient *http.Client
 
 	// The endpoint used to request WorkOS AuditLog events creation endpoint.
-	// Defaults to https://api.workos.com/audit_logs/events.
+	// Defaults to /audit_logs/events on the WORKOS_API_ENDPOINT environment
+	// variable when set, and to https://api.workos.com/audit_logs/events otherwise.
 	EventsEndpoint string
 
 	// The endpoint used to request WorkOS AuditLog events creation endpoint.
-	// Defaults to https://api.workos.com/audit_logs/exports.
+	// Defaults to /audit_logs/exports on the WORKOS_API_ENDPOINT environment
+	// variable when set, and to https://api.workos.com/audit_logs/exports otherwise.
 	ExportsEndpoint string
 
 	// The function used to encode in JSON. Defaults to json.Marshal.
 	JSONEncode func(v interface{}) ([]byte, error)
 
+	// When true, CreateEvent validates and encodes events without sending
+	// them to WorkOS. Useful to wire up event emission in testing or staging
+	// environments.
+	DryRun bool
+
+	// A function applied to the metadata of events, actors and targets before
+	// they are sent, eg. to redact personal information. It is only called with
+	// non-empty metadata, and with copies of it: the transformer can modify the
+	// maps it receives without altering the caller's events.
+	//
+	// OPTIONAL.
+	MetadataTransformer func(map[string]interface{}) map[string]interface{}
+
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
+// Option configures a Client created with NewClient.
+type Option func(*options)
+
+type options struct {
+	httpClient          *http.Client
+	timeout             time.Duration
+	hasTimeout          bool
+	maxIdleConnsPerHost int
+}
+
+// WithHTTPClient sets the http.Client used to send requests, replacing the
+// one tuned by NewClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of the requests sent by the Client. When
+// combined with WithHTTPClient, it applies to a copy of the given http.Client,
+// which is left unchanged.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+		o.hasTimeout = true
+	}
+}
+
+// WithMaxIdleConnsPerHost sets how many idle connections to WorkOS are kept
+// open for reuse. Raising it helps when emitting many events concurrently.
+// When combined with WithHTTPClient, it applies to a copy of the given
+// http.Client and of its http.Transport, which are left unchanged, and has no
+// effect when the client uses another kind of http.RoundTripper.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *options) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// NewClient returns a Client with the given API key.
+//
+// Its http.Client keeps connections to WorkOS alive between requests, which
+// suits high-volume event emission. It can be tuned with options, eg.
+// WithMaxIdleConnsPerHost, or replaced with WithHTTPClient. The options can be
+// given in any order.
+func NewClient(apiKey string, opts ...Option) *Client {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = 100
+		transport.MaxIdleConnsPerHost = 100
+		transport.IdleConnTimeout = 90 * time.Second
+
+		httpClient = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	} else if o.hasTimeout || o.maxIdleConnsPerHost > 0 {
+		// Never change a client the caller may share, eg. http.DefaultClient.
+		copied := *httpClient
+		httpClient = &copied
+	}
+
+	if o.hasTimeout {
+		httpClient.Timeout = o.timeout
+	}
+
+	if n := o.maxIdleConnsPerHost; n > 0 {
+		transport := httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		if t, ok := transport.(*http.Transport); ok {
+			t = t.Clone()
+			t.MaxIdleConnsPerHost = n
+			if t.MaxIdleConns < n {
+				t.MaxIdleConns = n
+			}
+			httpClient.Transport = t
+		}
+	}
+
+	return &Client{
+		APIKey:     apiKey,
+		HTTPClient: httpClient,
+	}
+}
+
 // CreateEventOpts represents arguments to create an Audit Logs event.
 type CreateEventOpts struct {
 	// Organization identifier
@@ -93,6 +225,46 @@ type Context struct {
 
 	// User Agent identity information of the event actor
 	UserAgent string `json:"user_agent"`
+
+	// Additional context fields, encoded alongside location and user_agent.
+	// Fields that are unknown to this package are decoded here so that they
+	// are not lost.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON encodes the context with its Extra fields flattened.
+func (c Context) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(c.Extra)+2)
+	for k, v := range c.Extra {
+		fields[k] = v
+	}
+	fields["location"] = c.Location
+	fields["user_agent"] = c.UserAgent
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the context, collecting the unknown fields in Extra.
+func (c *Context) UnmarshalJSON(data []byte) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*c = Context{}
+	for k, v := range fields {
+		switch k {
+		case "location":
+			c.Location, _ = v.(string)
+		case "user_agent":
+			c.UserAgent, _ = v.(string)
+		default:
+			if c.Extra == nil {
+				c.Extra = make(map[string]interface{})
+			}
+			c.Extra[k] = v
+		}
+	}
+	return nil
 }
 
 // Target describes event entity's
@@ -143,6 +315,26 @@ type CreateExportOpts struct {
 	Targets []string `json:"targets,omitempty"`
 }
 
+// ExportTimeFormat is the format of the RangeStart and RangeEnd of the
+// CreateExportOpts set with SetRange.
+const ExportTimeFormat = "2006-01-02T15:04:05Z"
+
+// SetRange sets RangeStart and RangeEnd from the given times, converted to UTC
+// and truncated to the second, eg. "2023-01-31T13:45:00Z", so that the range
+// has no time zone offset or fractional seconds. Zero times are rejected.
+func (e *CreateExportOpts) SetRange(start time.Time, end time.Time) error {
+	if start.IsZero() {
+		return errors.New("invalid arguments: zero range start")
+	}
+	if end.IsZero() {
+		return errors.New("invalid arguments: zero range end")
+	}
+
+	e.RangeStart = start.UTC().Truncate(time.Second).Format(ExportTimeFormat)
+	e.RangeEnd = end.UTC().Truncate(time.Second).Format(ExportTimeFormat)
+	return nil
+}
+
 // AuditLogExportState represents the active state of an AuditLogExport.
 type AuditLogExportState string
 
@@ -181,17 +373,79 @@ type GetExportOpts struct {
 	ExportID string `json:"export_id" binding:"required"`
 }
 
+// WaitForExportOpts contains the options to wait for an Audit Log Export.
+type WaitForExportOpts struct {
+	// The ID of the Audit Log Export to wait for.
+	ExportID string
+
+	// How long to wait for the export to be ready. Defaults to 5 minutes.
+	Timeout time.Duration
+
+	// The delay between two checks of the export state. Defaults to 1 second.
+	PollInterval time.Duration
+}
+
+// ErrExportTimeout is matched, with errors.Is, by the ExportTimeoutError
+// returned by WaitForExport.
+var ErrExportTimeout = errors.New("audit log export was not ready before the timeout")
+
+// ExportTimeoutError is returned by WaitForExport when the export is not ready
+// before the timeout.
+type ExportTimeoutError struct {
+	// The ID of the Audit Log Export.
+	ExportID string
+
+	// The last observed state of the Audit Log Export.
+	State AuditLogExportState
+}
+
+func (e ExportTimeoutError) Error() string {
+	return fmt.Sprintf("%s: export %s is %s", ErrExportTimeout, e.ExportID, e.State)
+}
+
+// Is reports whether target is ErrExportTimeout.
+func (e ExportTimeoutError) Is(target error) bool {
+	return target == ErrExportTimeout
+}
+
+// ListExportsOpts contains the options to list Audit Log Exports.
+type ListExportsOpts struct {
+	// Filter exports by Organization ID.
+	OrganizationID string `url:"organization_id,omitempty"`
+
+	// Maximum number of records to return.
+	Limit int `url:"limit"`
+
+	// The order in which to paginate records.
+	Order Order `url:"order,omitempty"`
+
+	// Pagination cursor to receive records before a provided Export ID.
+	Before string `url:"before,omitempty"`
+
+	// Pagination cursor to receive records after a provided Export ID.
+	After string `url:"after,omitempty"`
+}
+
+// ListExportsResponse contains the response from the ListExports call.
+type ListExportsResponse struct {
+	// List of Audit Log Exports.
+	Data []AuditLogExport `json:"data"`
+
+	// Cursor to paginate through the list of Audit Log Exports.
+	ListMetadata common.ListMetadata `json:"list_metadata"`
+}
+
 func (c *Client) init() {
 	if c.HTTPClient == nil {
 		c.HTTPClient = &http.Client{Timeout: 10 * time.Second}
 	}
 
 	if c.EventsEndpoint == "" {
-		c.EventsEndpoint = "https://api.workos.com/audit_logs/events"
+		c.EventsEndpoint = workos.DefaultEndpoint() + "/audit_logs/events"
 	}
 
 	if c.ExportsEndpoint == "" {
-		c.ExportsEndpoint = "https://api.workos.com/audit_logs/exports"
+		c.ExportsEndpoint = workos.DefaultEndpoint() + "/audit_logs/exports"
 	}
 
 	if c.JSONEncode == nil {
@@ -199,22 +453,53 @@ func (c *Client) init() {
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // CreateEvent creates an Audit Log event.
 func (c *Client) CreateEvent(ctx context.Context, e CreateEventOpts) error {
 	c.once.Do(c.init)
 
 	e.Event.OccurredAt = defaultTime(e.Event.OccurredAt)
 
+	if c.MetadataTransformer != nil {
+		e.Event = c.transformMetadata(e.Event)
+	}
+
+	if c.DryRun {
+		if e.OrganizationID == "" {
+			return errors.New("incomplete arguments: missing OrganizationID")
+		}
+		if e.Event.Action == "" {
+			return errors.New("incomplete arguments: missing Event.Action")
+		}
+	}
+
 	data, err := c.JSONEncode(e)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.EventsEndpoint, bytes.NewBuffer(data))
+	if c.DryRun {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.EventsEndpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -223,13 +508,84 @@ func (c *Client) CreateEvent(ctx context.Context, e CreateEventOpts) error {
 		req.Header.Set("Idempotency-Key", e.IdempotencyKey)
 	}
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
+}
+
+// OrganizationClient creates Audit Log events for a given Organization. It is
+// returned by Client.WithOrganization.
+type OrganizationClient struct {
+	client         *Client
+	organizationID string
+}
+
+// WithOrganization returns an OrganizationClient creating events for the given
+// Organization, eg. within the handler of a request scoped to an Organization.
+func (c *Client) WithOrganization(organizationID string) *OrganizationClient {
+	return &OrganizationClient{client: c, organizationID: organizationID}
+}
+
+// CreateEvent creates an Audit Log event. The OrganizationID of e defaults to
+// the Organization of the OrganizationClient when empty.
+func (c *OrganizationClient) CreateEvent(ctx context.Context, e CreateEventOpts) error {
+	if e.OrganizationID == "" {
+		e.OrganizationID = c.organizationID
+	}
+	return c.client.CreateEvent(ctx, e)
+}
+
+// transformMetadata returns a copy of the event whose metadata went through the
+// MetadataTransformer, leaving the caller's metadata and targets untouched.
+func (c *Client) transformMetadata(e Event) Event {
+	transform := func(m map[string]interface{}) map[string]interface{} {
+		if len(m) == 0 {
+			return m
+		}
+		return c.MetadataTransformer(copyMetadata(m))
+	}
+
+	e.Metadata = transform(e.Metadata)
+	e.Actor.Metadata = transform(e.Actor.Metadata)
+
+	targets := make([]Target, len(e.Targets))
+	for i, t := range e.Targets {
+		t.Metadata = transform(t.Metadata)
+		targets[i] = t
+	}
+	if e.Targets != nil {
+		e.Targets = targets
+	}
+	return e
+}
+
+// copyMetadata returns a deep copy of the given metadata, so that nested maps and
+// slices can be modified without altering the original.
+func copyMetadata(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = copyMetadataValue(v)
+	}
+	return copied
+}
+
+func copyMetadataValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return copyMetadata(v)
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyMetadataValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
 }
 
 // CreateExport creates an export of Audit Log events. You can specify some filters.
@@ -241,22 +597,21 @@ func (c *Client) CreateExport(ctx context.Context, e CreateExportOpts) (AuditLog
 		return AuditLogExport{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.ExportsEndpoint, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.ExportsEndpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return AuditLogExport{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuditLogExport{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return AuditLogExport{}, err
 	}
 
@@ -270,22 +625,21 @@ func (c *Client) CreateExport(ctx context.Context, e CreateExportOpts) (AuditLog
 func (c *Client) GetExport(ctx context.Context, e GetExportOpts) (AuditLogExport, error) {
 	c.once.Do(c.init)
 
-	req, err := http.NewRequest(http.MethodGet, c.ExportsEndpoint+"/"+e.ExportID, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.ExportsEndpoint+"/"+e.ExportID, nil)
 	if err != nil {
 		return AuditLogExport{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuditLogExport{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return AuditLogExport{}, err
 	}
 
@@ -295,6 +649,117 @@ func (c *Client) GetExport(ctx context.Context, e GetExportOpts) (AuditLogExport
 	return body, err
 }
 
+// WaitForExport polls an Audit Log Export until it is ready and returns it.
+//
+// An ExportTimeoutError, matching ErrExportTimeout, is returned when the export
+// is not ready before opts.Timeout, while ctx.Err() is returned when ctx is done
+// first. An error is also returned when the export failed.
+func (c *Client) WaitForExport(ctx context.Context, opts WaitForExportOpts) (AuditLogExport, error) {
+	if opts.ExportID == "" {
+		return AuditLogExport{}, errors.New("incomplete arguments: missing ExportID")
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = time.Second
+	}
+
+	timeout := time.NewTimer(opts.Timeout)
+	defer timeout.Stop()
+
+	for {
+		export, err := c.GetExport(ctx, GetExportOpts{ExportID: opts.ExportID})
+		if err != nil {
+			return AuditLogExport{}, err
+		}
+
+		switch {
+		case strings.EqualFold(string(export.State), string(Ready)):
+			return export, nil
+		case strings.EqualFold(string(export.State), string(Error)):
+			return export, fmt.Errorf("audit log export %s failed", export.ID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return AuditLogExport{}, ctx.Err()
+		case <-timeout.C:
+			return export, ExportTimeoutError{ExportID: opts.ExportID, State: export.State}
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// ListExports lists the Audit Log Exports matching the given options.
+func (c *Client) ListExports(ctx context.Context, opts ListExportsOpts) (ListExportsResponse, error) {
+	c.once.Do(c.init)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.ExportsEndpoint, nil)
+	if err != nil {
+		return ListExportsResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+
+	if opts.Limit == 0 {
+		opts.Limit = ResponseLimit
+	}
+
+	queryValues, err := query.Values(opts)
+	if err != nil {
+		return ListExportsResponse{}, err
+	}
+	req.URL.RawQuery = queryValues.Encode()
+
+	res, err := c.do(req)
+	if err != nil {
+		return ListExportsResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return ListExportsResponse{}, err
+	}
+
+	var body ListExportsResponse
+	dec := json.NewDecoder(res.Body)
+	err = dec.Decode(&body)
+	return body, err
+}
+
+// DownloadExportTo streams the contents of a ready Audit Log Export to w and
+// returns the number of bytes written, without loading the whole export in
+// memory.
+//
+// The export URL is presigned, so the request is sent without the API key.
+func (c *Client) DownloadExportTo(ctx context.Context, export AuditLogExport, w io.Writer) (int64, error) {
+	c.once.Do(c.init)
+
+	if export.URL == "" {
+		return 0, fmt.Errorf("audit log export %s has no URL, it is %s", export.ID, export.State)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, export.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return 0, fmt.Errorf("audit log export %s download failed: %s", export.ID, res.Status)
+	}
+
+	return io.Copy(w, res.Body)
+}
+
 func defaultTime(t time.Time) time.Time {
 	if t == (time.Time{}) {
 		t = time.Now().UTC()
@@ -1,15 +1,19 @@
 package auditlogs
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/workos/workos-go/v3/pkg/workos_errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/workos/workos-go/v3/pkg/common"
 )
 
 var event = CreateEventOpts{
@@ -39,6 +43,110 @@ var event = CreateEventOpts{
 	IdempotencyKey: "key",
 }
 
+func TestNewClient(t *testing.T) {
+	t.Run("Defaults keep connections alive", func(t *testing.T) {
+		client := NewClient("test")
+		require.Equal(t, "test", client.APIKey)
+		require.Equal(t, 10*time.Second, client.HTTPClient.Timeout)
+
+		transport := client.HTTPClient.Transport.(*http.Transport)
+		require.Equal(t, 100, transport.MaxIdleConnsPerHost)
+	})
+
+	t.Run("Options are applied", func(t *testing.T) {
+		client := NewClient("test",
+			WithTimeout(time.Second),
+			WithMaxIdleConnsPerHost(500),
+		)
+		require.Equal(t, time.Second, client.HTTPClient.Timeout)
+
+		transport := client.HTTPClient.Transport.(*http.Transport)
+		require.Equal(t, 500, transport.MaxIdleConnsPerHost)
+		require.Equal(t, 500, transport.MaxIdleConns)
+	})
+
+	t.Run("Constructed client sends events and exports", func(t *testing.T) {
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer test", r.Header.Get("Authorization"))
+			paths = append(paths, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":"audit_log_export_123"}`))
+		}))
+		defer server.Close()
+
+		client := NewClient("test", WithHTTPClient(server.Client()))
+		client.EventsEndpoint = server.URL + "/audit_logs/events"
+		client.ExportsEndpoint = server.URL + "/audit_logs/exports"
+
+		err := client.CreateEvent(context.Background(), event)
+		require.NoError(t, err)
+
+		export, err := client.GetExport(context.Background(), GetExportOpts{ExportID: "audit_log_export_123"})
+		require.NoError(t, err)
+		require.Equal(t, "audit_log_export_123", export.ID)
+		require.Equal(t, []string{"/audit_logs/events", "/audit_logs/exports/audit_log_export_123"}, paths)
+	})
+
+	t.Run("Constructed client defaults its endpoints", func(t *testing.T) {
+		client := NewClient("test", WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			require.Equal(t, "https://api.workos.com/audit_logs/events", r.URL.String())
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}}, nil
+		})}))
+
+		err := client.CreateEvent(context.Background(), event)
+		require.NoError(t, err)
+	})
+
+	t.Run("HTTP client can be replaced", func(t *testing.T) {
+		httpClient := &http.Client{}
+
+		client := NewClient("test", WithHTTPClient(httpClient))
+		require.Same(t, httpClient, client.HTTPClient)
+	})
+
+	t.Run("Options leave a shared HTTP client unchanged", func(t *testing.T) {
+		defaultTransport := http.DefaultTransport.(*http.Transport)
+		maxIdleConnsPerHost := defaultTransport.MaxIdleConnsPerHost
+
+		for _, opts := range [][]Option{
+			{WithHTTPClient(http.DefaultClient), WithTimeout(time.Second), WithMaxIdleConnsPerHost(500)},
+			{WithTimeout(time.Second), WithMaxIdleConnsPerHost(500), WithHTTPClient(http.DefaultClient)},
+		} {
+			client := NewClient("test", opts...)
+			require.NotSame(t, http.DefaultClient, client.HTTPClient)
+			require.Equal(t, time.Second, client.HTTPClient.Timeout)
+
+			transport := client.HTTPClient.Transport.(*http.Transport)
+			require.NotSame(t, defaultTransport, transport)
+			require.Equal(t, 500, transport.MaxIdleConnsPerHost)
+		}
+
+		require.Zero(t, http.DefaultClient.Timeout)
+		require.Nil(t, http.DefaultClient.Transport)
+		require.Equal(t, maxIdleConnsPerHost, defaultTransport.MaxIdleConnsPerHost)
+	})
+}
+
+func BenchmarkCreateEventParallel(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.EventsEndpoint = server.URL
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := client.CreateEvent(context.Background(), event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func TestCreateEvent(t *testing.T) {
 	t.Run("Idempotency Key is sent in the header", func(t *testing.T) {
 		handler := defaultTestHandler{}
@@ -154,6 +262,232 @@ func TestCreateEvent(t *testing.T) {
 		require.Equal(t, httpError.FieldErrors, []workos_errors.FieldError{workos_errors.FieldError{Field: "name", Code: "required_field"}})
 		require.Equal(t, httpError.ErrorCode, "invalid_audit_log")
 	})
+
+	t.Run("Dry run does not send the event", func(t *testing.T) {
+		requests := 0
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusOK)
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		client := &Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+			DryRun:         true,
+		}
+
+		err := client.CreateEvent(context.TODO(), event)
+		require.NoError(t, err)
+		require.Equal(t, 0, requests)
+	})
+
+	t.Run("Metadata transformer is applied before sending", func(t *testing.T) {
+		var sent CreateEventOpts
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.WriteHeader(http.StatusOK)
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		client := &Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+			MetadataTransformer: func(m map[string]interface{}) map[string]interface{} {
+				redacted := make(map[string]interface{}, len(m))
+				for k, v := range m {
+					if k != "email" {
+						redacted[k] = v
+					}
+				}
+				return redacted
+			},
+		}
+
+		opts := event
+		opts.Event.Metadata = map[string]interface{}{"successful": true, "email": "jon@foo-corp.com"}
+		opts.Event.Actor.Metadata = map[string]interface{}{"email": "jon@foo-corp.com"}
+		opts.Event.Targets = []Target{
+			{ID: "document_39127", Type: "document", Metadata: map[string]interface{}{"email": "jon@foo-corp.com", "pages": 3.0}},
+		}
+
+		err := client.CreateEvent(context.TODO(), opts)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"successful": true}, sent.Event.Metadata)
+		require.Empty(t, sent.Event.Actor.Metadata)
+		require.Equal(t, map[string]interface{}{"pages": 3.0}, sent.Event.Targets[0].Metadata)
+		require.Contains(t, opts.Event.Targets[0].Metadata, "email")
+	})
+
+	t.Run("Metadata transformer cannot alter the caller's event", func(t *testing.T) {
+		var sent CreateEventOpts
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.WriteHeader(http.StatusOK)
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		client := &Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+			MetadataTransformer: func(m map[string]interface{}) map[string]interface{} {
+				delete(m, "email")
+				if user, ok := m["user"].(map[string]interface{}); ok {
+					delete(user, "email")
+				}
+				return m
+			},
+		}
+
+		opts := event
+		opts.Event.Metadata = map[string]interface{}{
+			"successful": true,
+			"email":      "jon@foo-corp.com",
+			"user":       map[string]interface{}{"id": "user_123", "email": "jon@foo-corp.com"},
+		}
+		opts.Event.Actor.Metadata = map[string]interface{}{"email": "jon@foo-corp.com"}
+		opts.Event.Targets = []Target{
+			{ID: "document_39127", Type: "document", Metadata: map[string]interface{}{"email": "jon@foo-corp.com"}},
+		}
+
+		err := client.CreateEvent(context.TODO(), opts)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"successful": true,
+			"user":       map[string]interface{}{"id": "user_123"},
+		}, sent.Event.Metadata)
+
+		require.Equal(t, map[string]interface{}{
+			"successful": true,
+			"email":      "jon@foo-corp.com",
+			"user":       map[string]interface{}{"id": "user_123", "email": "jon@foo-corp.com"},
+		}, opts.Event.Metadata)
+		require.Equal(t, map[string]interface{}{"email": "jon@foo-corp.com"}, opts.Event.Actor.Metadata)
+		require.Equal(t, map[string]interface{}{"email": "jon@foo-corp.com"}, opts.Event.Targets[0].Metadata)
+	})
+
+	t.Run("Dry run validates the event", func(t *testing.T) {
+		client := &Client{
+			APIKey: "test",
+			DryRun: true,
+		}
+
+		err := client.CreateEvent(context.TODO(), CreateEventOpts{})
+		require.Error(t, err)
+	})
+	t.Run("Context extra fields are sent", func(t *testing.T) {
+		var sent CreateEventOpts
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.WriteHeader(http.StatusOK)
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		client := &Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+		}
+
+		opts := event
+		opts.Event.Context.Extra = map[string]interface{}{"device": "mobile"}
+
+		require.NoError(t, client.CreateEvent(context.TODO(), opts))
+		require.Equal(t, opts.Event.Context, sent.Event.Context)
+	})
+
+	t.Run("Organization client defaults the OrganizationID", func(t *testing.T) {
+		var sent []string
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			var opts CreateEventOpts
+			json.NewDecoder(r.Body).Decode(&opts)
+			sent = append(sent, opts.OrganizationID)
+			w.WriteHeader(http.StatusOK)
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		client := (&Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+		}).WithOrganization("org_default")
+
+		opts := event
+		opts.OrganizationID = ""
+		require.NoError(t, client.CreateEvent(context.TODO(), opts))
+
+		opts.OrganizationID = "org_explicit"
+		require.NoError(t, client.CreateEvent(context.TODO(), opts))
+
+		require.Equal(t, []string{"org_default", "org_explicit"}, sent)
+	})
+}
+
+func TestCreateExportOptsSetRange(t *testing.T) {
+	paris := time.FixedZone("CET", 60*60)
+
+	tests := []struct {
+		scenario      string
+		start         time.Time
+		end           time.Time
+		expectedStart string
+		expectedEnd   string
+		err           bool
+	}{
+		{
+			scenario:      "Fractional seconds are truncated",
+			start:         time.Date(2023, 1, 31, 13, 45, 0, 999999999, time.UTC),
+			end:           time.Date(2023, 2, 1, 8, 0, 59, 500000000, time.UTC),
+			expectedStart: "2023-01-31T13:45:00Z",
+			expectedEnd:   "2023-02-01T08:00:59Z",
+		},
+		{
+			scenario:      "Times are converted to UTC",
+			start:         time.Date(2023, 1, 1, 0, 30, 15, 123456789, paris),
+			end:           time.Date(2023, 1, 2, 0, 0, 0, 0, paris),
+			expectedStart: "2022-12-31T23:30:15Z",
+			expectedEnd:   "2023-01-01T23:00:00Z",
+		},
+		{
+			scenario: "Zero start is rejected",
+			end:      time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			err:      true,
+		},
+		{
+			scenario: "Zero end is rejected",
+			start:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts CreateExportOpts
+			err := opts.SetRange(test.start, test.end)
+			if test.err {
+				require.Error(t, err)
+				require.Empty(t, opts.RangeStart)
+				require.Empty(t, opts.RangeEnd)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedStart, opts.RangeStart)
+			require.Equal(t, test.expectedEnd, opts.RangeEnd)
+		})
+	}
 }
 
 func TestCreateExports(t *testing.T) {
@@ -280,6 +614,208 @@ func TestGetExports(t *testing.T) {
 	})
 }
 
+func TestWaitForExport(t *testing.T) {
+	newClient := func(states ...AuditLogExportState) (*Client, *httptest.Server) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := states[len(states)-1]
+			if calls < len(states) {
+				state = states[calls]
+			}
+			calls++
+
+			json.NewEncoder(w).Encode(AuditLogExport{ID: "audit_log_export_123", State: state})
+		}))
+
+		return &Client{
+			APIKey:          "test",
+			HTTPClient:      server.Client(),
+			ExportsEndpoint: server.URL,
+		}, server
+	}
+
+	t.Run("Ready export is returned", func(t *testing.T) {
+		client, server := newClient(Pending, Pending, "ready")
+		defer server.Close()
+
+		export, err := client.WaitForExport(context.TODO(), WaitForExportOpts{
+			ExportID:     "audit_log_export_123",
+			PollInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+		require.Equal(t, AuditLogExportState("ready"), export.State)
+	})
+
+	t.Run("Export stuck in pending returns ErrExportTimeout", func(t *testing.T) {
+		client, server := newClient(Pending)
+		defer server.Close()
+
+		_, err := client.WaitForExport(context.TODO(), WaitForExportOpts{
+			ExportID:     "audit_log_export_123",
+			Timeout:      20 * time.Millisecond,
+			PollInterval: time.Millisecond,
+		})
+		require.True(t, errors.Is(err, ErrExportTimeout))
+		require.Equal(t, ExportTimeoutError{ExportID: "audit_log_export_123", State: Pending}, err)
+	})
+
+	t.Run("Canceled context returns the context error", func(t *testing.T) {
+		client, server := newClient(Pending)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.WaitForExport(ctx, WaitForExportOpts{
+			ExportID:     "audit_log_export_123",
+			Timeout:      time.Minute,
+			PollInterval: time.Millisecond,
+		})
+		require.False(t, errors.Is(err, ErrExportTimeout))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("Failed export returns an error", func(t *testing.T) {
+		client, server := newClient(Error)
+		defer server.Close()
+
+		_, err := client.WaitForExport(context.TODO(), WaitForExportOpts{ExportID: "audit_log_export_123"})
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrExportTimeout))
+	})
+}
+
+func TestDownloadExportTo(t *testing.T) {
+	t.Run("Export is streamed to the writer", func(t *testing.T) {
+		contents := `{"id":"event_1","action":"user.signed_in"}` + "\n" + `{"id":"event_2","action":"user.signed_out"}` + "\n"
+
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.Write([]byte(contents))
+		}))
+		defer server.Close()
+
+		client := &Client{
+			APIKey:     "test",
+			HTTPClient: server.Client(),
+		}
+
+		var buf bytes.Buffer
+		n, err := client.DownloadExportTo(context.Background(), AuditLogExport{
+			ID:    "audit_log_export_123",
+			State: Ready,
+			URL:   server.URL + "/exports/audit_log_export_123.csv?signature=abc",
+		}, &buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(contents)), n)
+		require.Equal(t, contents, buf.String())
+		require.Empty(t, authorization)
+	})
+
+	t.Run("Failed download returns an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+		}))
+		defer server.Close()
+
+		client := &Client{HTTPClient: server.Client()}
+
+		var buf bytes.Buffer
+		n, err := client.DownloadExportTo(context.Background(), AuditLogExport{
+			ID:  "audit_log_export_123",
+			URL: server.URL,
+		}, &buf)
+		require.Error(t, err)
+		require.Zero(t, n)
+		require.Zero(t, buf.Len())
+	})
+
+	t.Run("Export without URL returns an error", func(t *testing.T) {
+		client := &Client{}
+
+		_, err := client.DownloadExportTo(context.Background(), AuditLogExport{
+			ID:    "audit_log_export_123",
+			State: Pending,
+		}, &bytes.Buffer{})
+		require.Error(t, err)
+	})
+}
+
+func TestListExports(t *testing.T) {
+	t.Run("Call succeeds", func(t *testing.T) {
+		var query url.Values
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{
+				"object": "list",
+				"data": [
+					{"object": "audit_log_export", "id": "audit_log_export_1", "state": "ready", "url": "https://exports.workos.com/1.csv", "created_at": "2021-06-25T19:07:33.155Z", "updated_at": "2021-06-25T19:08:33.155Z"},
+					{"object": "audit_log_export", "id": "audit_log_export_2", "state": "pending", "created_at": "2021-06-26T19:07:33.155Z", "updated_at": "2021-06-26T19:07:33.155Z"}
+				],
+				"list_metadata": {"before": "", "after": "audit_log_export_2"}
+			}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		DefaultClient = &Client{
+			HTTPClient:      server.Client(),
+			ExportsEndpoint: server.URL,
+		}
+		SetAPIKey("test")
+
+		body, err := ListExports(context.TODO(), ListExportsOpts{OrganizationID: "org_123"})
+		require.NoError(t, err)
+		require.Equal(t, "org_123", query.Get("organization_id"))
+		require.Equal(t, "10", query.Get("limit"))
+		require.Equal(t, ListExportsResponse{
+			Data: []AuditLogExport{
+				{
+					Object:    AuditLogExportObjectName,
+					ID:        "audit_log_export_1",
+					State:     "ready",
+					URL:       "https://exports.workos.com/1.csv",
+					CreatedAt: "2021-06-25T19:07:33.155Z",
+					UpdatedAt: "2021-06-25T19:08:33.155Z",
+				},
+				{
+					Object:    AuditLogExportObjectName,
+					ID:        "audit_log_export_2",
+					State:     "pending",
+					CreatedAt: "2021-06-26T19:07:33.155Z",
+					UpdatedAt: "2021-06-26T19:07:33.155Z",
+				},
+			},
+			ListMetadata: common.ListMetadata{After: "audit_log_export_2"},
+		}, body)
+	})
+	t.Run("401 requests returns an error", func(t *testing.T) {
+		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		server := httptest.NewServer(http.HandlerFunc(handlerFunc))
+		defer server.Close()
+
+		DefaultClient = &Client{
+			HTTPClient:      server.Client(),
+			ExportsEndpoint: server.URL,
+		}
+		SetAPIKey("test")
+
+		_, err := ListExports(context.TODO(), ListExportsOpts{})
+		require.Error(t, err)
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 type defaultTestHandler struct {
 	header *http.Header
 }
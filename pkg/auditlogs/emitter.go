@@ -0,0 +1,117 @@
+package auditlogs
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// This represents the list of errors that could be raised when emitting events
+// with an AsyncEmitter.
+var (
+	ErrEmitterClosed     = errors.New("audit log emitter is closed")
+	ErrEmitterBufferFull = errors.New("audit log emitter buffer is full")
+)
+
+// AsyncEmitter creates Audit Log events in the background, so that emitting an
+// event does not delay the code emitting it. Events are sent one at a time, in
+// the order they were emitted.
+//
+// Close must be called on shutdown to send the buffered events.
+type AsyncEmitter struct {
+	client  *Client
+	onError func(CreateEventOpts, error)
+	events  chan CreateEventOpts
+
+	// ctx is canceled when Close gives up on draining, which aborts the
+	// in-flight request.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+
+	done      chan struct{}
+	undrained int
+}
+
+// NewAsyncEmitter returns an AsyncEmitter creating events with the given client
+// and buffering up to bufferSize events. onError, when not nil, is called with
+// every event that could not be created.
+func NewAsyncEmitter(client *Client, bufferSize int, onError func(CreateEventOpts, error)) *AsyncEmitter {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e := &AsyncEmitter{
+		client:  client,
+		onError: onError,
+		events:  make(chan CreateEventOpts, bufferSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Emit buffers an event to be created in the background. It never blocks: it
+// returns ErrEmitterBufferFull when the buffer is full, and ErrEmitterClosed
+// once Close was called.
+func (e *AsyncEmitter) Emit(opts CreateEventOpts) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closed {
+		return ErrEmitterClosed
+	}
+
+	select {
+	case e.events <- opts:
+		return nil
+	default:
+		return ErrEmitterBufferFull
+	}
+}
+
+// Close stops accepting events and waits for the buffered ones to be created.
+//
+// When ctx is done before all of them are, the in-flight request is canceled
+// and Close returns ctx.Err() along with the number of events that were not
+// created.
+func (e *AsyncEmitter) Close(ctx context.Context) (int, error) {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.events)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.undrained, nil
+	case <-ctx.Done():
+		e.cancel()
+		<-e.done
+		return e.undrained, ctx.Err()
+	}
+}
+
+func (e *AsyncEmitter) run() {
+	defer close(e.done)
+	defer e.cancel()
+
+	for opts := range e.events {
+		if e.ctx.Err() != nil {
+			e.undrained++
+			continue
+		}
+
+		err := e.client.CreateEvent(e.ctx, opts)
+		if err != nil && e.ctx.Err() != nil {
+			e.undrained++
+			continue
+		}
+		if err != nil && e.onError != nil {
+			e.onError(opts, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package auditlogs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncEmitter(t *testing.T) {
+	t.Run("Close drains the buffered events", func(t *testing.T) {
+		var mu sync.Mutex
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		emitter := NewAsyncEmitter(&Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+		}, 10, nil)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, emitter.Emit(event))
+		}
+
+		undrained, err := emitter.Close(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, undrained)
+		require.Equal(t, 3, requests)
+
+		require.Equal(t, ErrEmitterClosed, emitter.Emit(event))
+	})
+
+	t.Run("Close returns the undrained events when the deadline is exceeded", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		var failed int
+		emitter := NewAsyncEmitter(&Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+		}, 10, func(CreateEventOpts, error) { failed++ })
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, emitter.Emit(event))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		undrained, err := emitter.Close(ctx)
+		require.Equal(t, context.DeadlineExceeded, err)
+		require.Equal(t, 3, undrained)
+		require.Zero(t, failed)
+	})
+
+	t.Run("Emit does not block when the buffer is full", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+
+		emitter := NewAsyncEmitter(&Client{
+			APIKey:         "test",
+			HTTPClient:     server.Client(),
+			EventsEndpoint: server.URL,
+		}, 1, nil)
+
+		var err error
+		for i := 0; i < 3 && err == nil; i++ {
+			err = emitter.Emit(event)
+		}
+		require.Equal(t, ErrEmitterBufferFull, err)
+
+		close(release)
+		_, err = emitter.Close(context.Background())
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,113 @@
+package auditlogs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// AuditLogEvent is an Audit Log event as read from an export.
+type AuditLogEvent struct {
+	// The event's unique identifier.
+	ID string `json:"id"`
+
+	// Represents the activity performed by the actor.
+	Action string `json:"action"`
+
+	// The time when the event occurred.
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// Describes the entity that generated the event.
+	Actor Actor `json:"actor"`
+
+	// List of event targets.
+	Targets []Target `json:"targets"`
+
+	// Attributes of event context.
+	Context Context `json:"context"`
+
+	// Event metadata.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ParseExportedEvent decodes a single line of an export into an AuditLogEvent.
+func ParseExportedEvent(line []byte) (AuditLogEvent, error) {
+	var event AuditLogEvent
+	err := json.Unmarshal(line, &event)
+	return event, err
+}
+
+// ParseExport decodes the events of an export, which contains one JSON encoded
+// event per line. Empty lines are skipped.
+func ParseExport(r io.Reader) ([]AuditLogEvent, error) {
+	var events []AuditLogEvent
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		event, err := ParseExportedEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+// FilterEvents returns the events for which the predicate returns true.
+//
+// Exports cannot be filtered by metadata when they are created, so events of a
+// downloaded export are meant to be filtered with this function instead, eg.
+// with MetadataEquals.
+func FilterEvents(events []AuditLogEvent, predicate func(AuditLogEvent) bool) []AuditLogEvent {
+	var filtered []AuditLogEvent
+	for _, event := range events {
+		if predicate(event) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// MetadataEquals returns a FilterEvents predicate matching the events whose
+// metadata value for key is value.
+//
+// Values are compared as JSON values: numbers match whatever their Go type, eg.
+// 3 matches the 3.0 decoded from an export, and maps and slices match when
+// their contents are equal.
+func MetadataEquals(key string, value interface{}) func(AuditLogEvent) bool {
+	expected, err := normalizeJSON(value)
+	return func(event AuditLogEvent) bool {
+		v, ok := event.Metadata[key]
+		if !ok || err != nil {
+			return false
+		}
+
+		actual, err := normalizeJSON(v)
+		return err == nil && reflect.DeepEqual(actual, expected)
+	}
+}
+
+// normalizeJSON returns v as decoded from its JSON encoding, so that values
+// of different Go types with the same JSON representation can be compared.
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
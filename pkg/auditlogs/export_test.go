@@ -0,0 +1,121 @@
+package auditlogs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exportedEventLine = `{"id":"event_123","action":"document.updated","occurred_at":"2021-06-25T19:07:33.155Z","actor":{"id":"user_1","name":"Jon Smith","type":"User"},"targets":[{"id":"document_39127","type":"document"}],"context":{"location":"192.0.0.8","user_agent":"Firefox"},"metadata":{"successful":true}}`
+
+func TestParseExportedEvent(t *testing.T) {
+	event, err := ParseExportedEvent([]byte(exportedEventLine))
+	require.NoError(t, err)
+	require.Equal(t, AuditLogEvent{
+		ID:         "event_123",
+		Action:     "document.updated",
+		OccurredAt: time.Date(2021, 6, 25, 19, 7, 33, 155000000, time.UTC),
+		Actor: Actor{
+			ID:   "user_1",
+			Name: "Jon Smith",
+			Type: "User",
+		},
+		Targets: []Target{
+			{ID: "document_39127", Type: "document"},
+		},
+		Context: Context{
+			Location:  "192.0.0.8",
+			UserAgent: "Firefox",
+		},
+		Metadata: map[string]interface{}{
+			"successful": true,
+		},
+	}, event)
+}
+
+func TestContextExtraFields(t *testing.T) {
+	event, err := ParseExportedEvent([]byte(`{"id":"event_123","context":{"location":"192.0.0.8","user_agent":"Firefox","device":"mobile","geo":{"country":"DE"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, Context{
+		Location:  "192.0.0.8",
+		UserAgent: "Firefox",
+		Extra: map[string]interface{}{
+			"device": "mobile",
+			"geo":    map[string]interface{}{"country": "DE"},
+		},
+	}, event.Context)
+
+	data, err := json.Marshal(event.Context)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"location":"192.0.0.8","user_agent":"Firefox","device":"mobile","geo":{"country":"DE"}}`, string(data))
+
+	data, err = json.Marshal(Context{Location: "192.0.0.8", Extra: map[string]interface{}{"location": "ignored"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"location":"192.0.0.8","user_agent":""}`, string(data))
+}
+
+func TestParseExport(t *testing.T) {
+	t.Run("Every line is decoded", func(t *testing.T) {
+		export := exportedEventLine + "\n\n" + strings.Replace(exportedEventLine, "event_123", "event_456", 1) + "\n"
+
+		events, err := ParseExport(strings.NewReader(export))
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "event_123", events[0].ID)
+		require.Equal(t, "event_456", events[1].ID)
+	})
+
+	t.Run("Malformed line returns an error", func(t *testing.T) {
+		_, err := ParseExport(strings.NewReader(exportedEventLine + "\nnot json\n"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestFilterEvents(t *testing.T) {
+	events := []AuditLogEvent{
+		{ID: "event_1", Metadata: map[string]interface{}{"team": "billing"}},
+		{ID: "event_2", Metadata: map[string]interface{}{"team": "support"}},
+		{ID: "event_3"},
+		{ID: "event_4", Metadata: map[string]interface{}{"team": "billing", "successful": true}},
+	}
+
+	filtered := FilterEvents(events, MetadataEquals("team", "billing"))
+	require.Equal(t, []AuditLogEvent{events[0], events[3]}, filtered)
+
+	filtered = FilterEvents(events, MetadataEquals("successful", true))
+	require.Equal(t, []AuditLogEvent{events[3]}, filtered)
+
+	filtered = FilterEvents(events, MetadataEquals("team", "sales"))
+	require.Empty(t, filtered)
+}
+
+func TestFilterEventsNonStringMetadata(t *testing.T) {
+	events, err := ParseExport(strings.NewReader(
+		`{"id":"event_1","metadata":{"attempts":3,"location":{"city":"Berlin","tags":["eu"]}}}` + "\n" +
+			`{"id":"event_2","metadata":{"attempts":4,"location":{"city":"Paris","tags":["eu"]}}}` + "\n",
+	))
+	require.NoError(t, err)
+
+	filtered := FilterEvents(events, MetadataEquals("attempts", 3))
+	require.Equal(t, []AuditLogEvent{events[0]}, filtered)
+
+	filtered = FilterEvents(events, MetadataEquals("attempts", 4.0))
+	require.Equal(t, []AuditLogEvent{events[1]}, filtered)
+
+	filtered = FilterEvents(events, MetadataEquals("location", map[string]interface{}{
+		"city": "Berlin",
+		"tags": []string{"eu"},
+	}))
+	require.Equal(t, []AuditLogEvent{events[0]}, filtered)
+
+	// Comparing with an object does not panic on string values.
+	filtered = FilterEvents(events, MetadataEquals("attempts", map[string]interface{}{"city": "Berlin"}))
+	require.Empty(t, filtered)
+
+	filtered = FilterEvents(events, MetadataEquals("location", "Berlin"))
+	require.Empty(t, filtered)
+}
@@ -0,0 +1,19 @@
+package auditlogs
+
+import (
+	"context"
+	"io"
+)
+
+// AuditLogs is the interface implemented by Client. Code calling the Audit Logs
+// API can depend on it instead of Client, so that a mock can be injected in
+// tests.
+type AuditLogs interface {
+	CreateEvent(ctx context.Context, e CreateEventOpts) error
+	WithOrganization(organizationID string) *OrganizationClient
+	CreateExport(ctx context.Context, e CreateExportOpts) (AuditLogExport, error)
+	GetExport(ctx context.Context, e GetExportOpts) (AuditLogExport, error)
+	WaitForExport(ctx context.Context, opts WaitForExportOpts) (AuditLogExport, error)
+	ListExports(ctx context.Context, opts ListExportsOpts) (ListExportsResponse, error)
+	DownloadExportTo(ctx context.Context, export AuditLogExport, w io.Writer) (int64, error)
+}
@@ -0,0 +1,3 @@
+package auditlogs
+
+var _ AuditLogs = (*Client)(nil)
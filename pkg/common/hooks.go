@@ -0,0 +1,19 @@
+package common
+
+import (
+	"net/http"
+	"time"
+)
+
+// ErrorHandler is called by the clients with the response and the error of
+// every failed request, and returns the error to return instead, eg. to attach
+// application specific context or to record metrics.
+type ErrorHandler func(res *http.Response, err error) error
+
+// RequestCompleteFunc is called by the clients after every attempt at sending a
+// request with its method, path, attempt number, response status and duration,
+// eg. to record metrics. The status is zero when no response was received.
+//
+// The attempt number starts at 1. Requests retried by a retry.Transport are
+// reported once per attempt, with growing attempt numbers.
+type RequestCompleteFunc func(method, path string, attempt int, status int, duration time.Duration)
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"context"
+	"fmt"
+)
+
+// PageFetcher fetches the page of records following the given cursor, which is
+// empty for the first page, and returns the cursor of the next page. The
+// returned cursor is empty for the last page.
+//
+// The records of the page are expected to be collected by the function itself,
+// eg. by appending them to a slice.
+type PageFetcher func(ctx context.Context, after string) (next string, err error)
+
+// Paginate calls fetch for every page of a cursor paginated list, from the
+// first one to the last one. It stops at the first error returned by fetch, and
+// with ctx.Err() when ctx is done between two pages.
+func Paginate(ctx context.Context, fetch PageFetcher) error {
+	seen := make(map[string]bool)
+	after := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next, err := fetch(ctx, after)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+
+		if seen[next] {
+			return fmt.Errorf("pagination cursor %q was returned twice", next)
+		}
+		seen[next] = true
+		after = next
+	}
+}
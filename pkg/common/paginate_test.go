@@ -0,0 +1,74 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := map[string]struct {
+		data []string
+		next string
+	}{
+		"":  {data: []string{"a", "b"}, next: "b"},
+		"b": {data: []string{"c", "d"}, next: "d"},
+		"d": {data: []string{"e"}},
+	}
+
+	t.Run("Every page is fetched", func(t *testing.T) {
+		var data []string
+		var cursors []string
+
+		err := Paginate(context.Background(), func(ctx context.Context, after string) (string, error) {
+			cursors = append(cursors, after)
+			page := pages[after]
+			data = append(data, page.data...)
+			return page.next, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"", "b", "d"}, cursors)
+		require.Equal(t, []string{"a", "b", "c", "d", "e"}, data)
+	})
+
+	t.Run("Fetch error stops the pagination", func(t *testing.T) {
+		fetchErr := errors.New("fetch error")
+		calls := 0
+
+		err := Paginate(context.Background(), func(ctx context.Context, after string) (string, error) {
+			calls++
+			if after == "b" {
+				return "", fetchErr
+			}
+			return pages[after].next, nil
+		})
+		require.Equal(t, fetchErr, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Repeated cursor returns an error", func(t *testing.T) {
+		calls := 0
+
+		err := Paginate(context.Background(), func(ctx context.Context, after string) (string, error) {
+			calls++
+			return "loop", nil
+		})
+		require.Error(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Canceled context stops the pagination", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+
+		err := Paginate(ctx, func(ctx context.Context, after string) (string, error) {
+			calls++
+			cancel()
+			return pages[after].next, nil
+		})
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, 1, calls)
+	})
+}
@@ -3,13 +3,13 @@ package directorysync
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
 
 	"github.com/workos/workos-go/v3/internal/workos"
 	"github.com/workos/workos-go/v3/pkg/common"
@@ -36,9 +36,32 @@ type Client struct {
 	// Defaults to http.Client.
 	HTTPClient *http.Client
 
-	// The endpoint to WorkOS API. Defaults to https://api.workos.com.
+	// The endpoint to WorkOS API. Defaults to the WORKOS_API_ENDPOINT
+	// environment variable when set, and to https://api.workos.com otherwise.
 	Endpoint string
 
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
@@ -48,10 +71,25 @@ func (c *Client) init() {
 	}
 
 	if c.Endpoint == "" {
-		c.Endpoint = "https://api.workos.com"
+		c.Endpoint = workos.DefaultEndpoint()
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // UserEmail contains data about a Directory User's e-mail address.
 type UserEmail struct {
 	// Flag to indicate if this e-mail is primary.
@@ -161,7 +199,7 @@ type ListUsersResponse struct {
 	Data []User `json:"data"`
 
 	// Cursor pagination options.
-	ListMetadata common.ListMetadata `json:"listMetadata"`
+	ListMetadata common.ListMetadata `json:"list_metadata"`
 }
 
 // ListUsers gets a list of provisioned Users for a Directory.
@@ -172,7 +210,8 @@ func (c *Client) ListUsers(
 	c.once.Do(c.init)
 
 	endpoint := fmt.Sprintf("%s/directory_users", c.Endpoint)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -181,7 +220,6 @@ func (c *Client) ListUsers(
 		return ListUsersResponse{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -195,13 +233,13 @@ func (c *Client) ListUsers(
 	}
 
 	req.URL.RawQuery = v.Encode()
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListUsersResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListUsersResponse{}, err
 	}
 
@@ -243,7 +281,7 @@ type ListGroupsOpts struct {
 	// Directory unique identifier.
 	Directory string `url:"directory,omitempty"`
 
-	// Directory unique identifier.
+	// Directory User unique identifier.
 	User string `url:"user,omitempty"`
 
 	// Maximum number of records to return.
@@ -266,7 +304,7 @@ type ListGroupsResponse struct {
 	Data []Group `json:"data"`
 
 	// Cursor pagination options.
-	ListMetadata common.ListMetadata `json:"listMetadata"`
+	ListMetadata common.ListMetadata `json:"list_metadata"`
 }
 
 // ListGroups gets a list of provisioned Groups for a Directory Endpoint.
@@ -277,7 +315,8 @@ func (c *Client) ListGroups(
 	c.once.Do(c.init)
 
 	endpoint := fmt.Sprintf("%s/directory_groups", c.Endpoint)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -286,7 +325,6 @@ func (c *Client) ListGroups(
 		return ListGroupsResponse{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -301,13 +339,13 @@ func (c *Client) ListGroups(
 	}
 
 	req.URL.RawQuery = v.Encode()
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListGroupsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListGroupsResponse{}, err
 	}
 
@@ -317,6 +355,32 @@ func (c *Client) ListGroups(
 	return body, err
 }
 
+// ListUserGroups gets every Group a provisioned Directory User belongs to,
+// following pagination cursors, eg. to map the Groups to roles.
+func (c *Client) ListUserGroups(ctx context.Context, directoryUserID string) ([]Group, error) {
+	if directoryUserID == "" {
+		return nil, errors.New("incomplete arguments: missing directory user ID")
+	}
+
+	var groups []Group
+
+	err := common.Paginate(ctx, func(ctx context.Context, after string) (string, error) {
+		res, err := c.ListGroups(ctx, ListGroupsOpts{
+			User:  directoryUserID,
+			After: after,
+		})
+		if err != nil {
+			return "", err
+		}
+		groups = append(groups, res.Data...)
+		return res.ListMetadata.After, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
 // GetUserOpts contains the options to request details for a provisioned Directory User.
 type GetUserOpts struct {
 	// Directory User unique identifier.
@@ -335,7 +399,8 @@ func (c *Client) GetUser(
 		c.Endpoint,
 		opts.User,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -344,18 +409,17 @@ func (c *Client) GetUser(
 		return User{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return User{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return User{}, err
 	}
 
@@ -383,7 +447,8 @@ func (c *Client) GetGroup(
 		c.Endpoint,
 		opts.Group,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -392,18 +457,17 @@ func (c *Client) GetGroup(
 		return Group{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Group{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Group{}, err
 	}
 
@@ -513,7 +577,7 @@ type ListDirectoriesResponse struct {
 	Data []Directory `json:"data"`
 
 	// Cursor pagination options.
-	ListMetadata common.ListMetadata `json:"listMetadata"`
+	ListMetadata common.ListMetadata `json:"list_metadata"`
 }
 
 // ListDirectories gets details of existing Directories.
@@ -524,7 +588,8 @@ func (c *Client) ListDirectories(
 	c.once.Do(c.init)
 
 	endpoint := fmt.Sprintf("%s/directories", c.Endpoint)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -533,7 +598,6 @@ func (c *Client) ListDirectories(
 		return ListDirectoriesResponse{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -547,13 +611,13 @@ func (c *Client) ListDirectories(
 	}
 
 	req.URL.RawQuery = v.Encode()
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListDirectoriesResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListDirectoriesResponse{}, err
 	}
 	var body ListDirectoriesResponse
@@ -580,7 +644,8 @@ func (c *Client) GetDirectory(
 		c.Endpoint,
 		opts.Directory,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -589,18 +654,17 @@ func (c *Client) GetDirectory(
 		return Directory{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Directory{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Directory{}, err
 	}
 
@@ -628,7 +692,8 @@ func (c *Client) DeleteDirectory(
 		c.Endpoint,
 		opts.Directory,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodDelete,
 		endpoint,
 		nil,
@@ -637,16 +702,15 @@ func (c *Client) DeleteDirectory(
 		return err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
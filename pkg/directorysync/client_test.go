@@ -248,6 +248,54 @@ func listGroupsTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestListUserGroups(t *testing.T) {
+	var users []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test" {
+			http.Error(w, "bad auth", http.StatusUnauthorized)
+			return
+		}
+		users = append(users, r.URL.Query().Get("user"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("after") == "directory_group_1" {
+			w.Write([]byte(`{
+				"object": "list",
+				"data": [
+					{"id": "directory_group_2", "name": "Engineers", "idp_id": "456", "directory_id": "directory_123", "organization_id": "org_123"}
+				],
+				"list_metadata": {"before": "directory_group_2", "after": null}
+			}`))
+			return
+		}
+		w.Write([]byte(`{
+			"object": "list",
+			"data": [
+				{"id": "directory_group_1", "name": "Scientists", "idp_id": "123", "directory_id": "directory_123", "organization_id": "org_123"}
+			],
+			"list_metadata": {"before": null, "after": "directory_group_1"}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		APIKey:     "test",
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+	}
+
+	groups, err := client.ListUserGroups(context.Background(), "directory_user_123")
+	require.NoError(t, err)
+	require.Equal(t, []Group{
+		{ID: "directory_group_1", Name: "Scientists", IdpID: "123", DirectoryID: "directory_123", OrganizationID: "org_123"},
+		{ID: "directory_group_2", Name: "Engineers", IdpID: "456", DirectoryID: "directory_123", OrganizationID: "org_123"},
+	}, groups)
+	require.Equal(t, []string{"directory_user_123", "directory_user_123"}, users)
+
+	_, err = client.ListUserGroups(context.Background(), "")
+	require.Error(t, err)
+}
+
 func TestGetUser(t *testing.T) {
 	tests := []struct {
 		scenario string
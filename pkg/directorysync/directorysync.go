@@ -8,9 +8,7 @@ import (
 
 // DefaultClient is the client used by SetAPIKey and Directory Sync functions.
 var (
-	DefaultClient = &Client{
-		Endpoint: "https://api.workos.com",
-	}
+	DefaultClient = &Client{}
 )
 
 // SetAPIKey sets the WorkOS API key for Directory Sync requests.
@@ -34,6 +32,14 @@ func ListGroups(
 	return DefaultClient.ListGroups(ctx, opts)
 }
 
+// ListUserGroups gets every Group a provisioned Directory User belongs to.
+func ListUserGroups(
+	ctx context.Context,
+	directoryUserID string,
+) ([]Group, error) {
+	return DefaultClient.ListUserGroups(ctx, directoryUserID)
+}
+
 // GetUser gets a provisioned User for a Directory.
 func GetUser(
 	ctx context.Context,
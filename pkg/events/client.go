@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/google/go-querystring/query"
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
 
 	"github.com/workos/workos-go/v3/internal/workos"
 	"github.com/workos/workos-go/v3/pkg/common"
@@ -42,6 +41,11 @@ const (
 	UserDeleted                   = "user.deleted"
 	OrganizationMembershipAdded   = "organization_membership.added"
 	OrganizationMembershipRemoved = "organization_membership.removed"
+	OrganizationMembershipCreated = "organization_membership.created"
+	OrganizationMembershipUpdated = "organization_membership.updated"
+	OrganizationMembershipDeleted = "organization_membership.deleted"
+	// Audit Log Events
+	AuditLogExportCompleted = "audit_log_export.completed"
 )
 
 // Client represents a client that performs Event requests to the WorkOS API.
@@ -53,9 +57,32 @@ type Client struct {
 	// Defaults to http.Client.
 	HTTPClient *http.Client
 
-	// The endpoint to WorkOS API. Defaults to https://api.workos.com.
+	// The endpoint to WorkOS API. Defaults to the WORKOS_API_ENDPOINT
+	// environment variable when set, and to https://api.workos.com otherwise.
 	Endpoint string
 
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
@@ -65,10 +92,25 @@ func (c *Client) init() {
 	}
 
 	if c.Endpoint == "" {
-		c.Endpoint = "https://api.workos.com"
+		c.Endpoint = workos.DefaultEndpoint()
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // Event contains data about a particular Event.
 type Event struct {
 	// The Event's unique identifier.
@@ -120,7 +162,8 @@ func (c *Client) ListEvents(
 	c.once.Do(c.init)
 
 	endpoint := fmt.Sprintf("%s/events", c.Endpoint)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -129,7 +172,6 @@ func (c *Client) ListEvents(
 		return ListEventsResponse{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -143,13 +185,13 @@ func (c *Client) ListEvents(
 	}
 
 	req.URL.RawQuery = queryValues.Encode()
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListEventsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListEventsResponse{}, err
 	}
 
@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -78,6 +79,44 @@ func TestListEvents(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, expectedResponse, events)
 	})
+
+	t.Run("ListEvents sends the configured API version", func(t *testing.T) {
+		var version string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version = r.Header.Get("WorkOS-Version")
+			ListEventsTestHandler(w, r)
+		}))
+		defer server.Close()
+		client := &Client{
+			HTTPClient: server.Client(),
+			Endpoint:   server.URL,
+			APIKey:     "test",
+			APIVersion: "2024-01-01",
+		}
+
+		_, err := client.ListEvents(context.Background(), ListEventsOpts{})
+
+		require.NoError(t, err)
+		require.Equal(t, "2024-01-01", version)
+	})
+
+	t.Run("ListEvents passes errors through the ErrorHandler", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(ListEventsTestHandler))
+		defer server.Close()
+
+		handledErr := errors.New("handled error")
+		client := &Client{
+			HTTPClient: server.Client(),
+			Endpoint:   server.URL,
+			APIKey:     "invalid",
+			ErrorHandler: func(res *http.Response, err error) error {
+				return handledErr
+			},
+		}
+
+		_, err := client.ListEvents(context.Background(), ListEventsOpts{})
+		require.Equal(t, handledErr, err)
+	})
 }
 
 func ListEventsTestHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OrganizationMembershipRole contains the role of an Organization Membership.
+type OrganizationMembershipRole struct {
+	// The slug of the role, eg. "member" or "admin".
+	Slug string `json:"slug"`
+}
+
+// OrganizationMembershipData is the payload of the organization_membership
+// Events.
+type OrganizationMembershipData struct {
+	// The Organization Membership's unique identifier.
+	ID string `json:"id"`
+
+	// The ID of the User.
+	UserID string `json:"user_id"`
+
+	// The ID of the Organization.
+	OrganizationID string `json:"organization_id"`
+
+	// The User's role in the Organization.
+	Role OrganizationMembershipRole `json:"role"`
+
+	// The status of the Organization Membership, eg. "active".
+	Status string `json:"status"`
+
+	// The timestamp of when the Organization Membership was created.
+	CreatedAt string `json:"created_at"`
+
+	// The timestamp of when the Organization Membership was updated.
+	UpdatedAt string `json:"updated_at"`
+}
+
+// AuditLogExportData is the payload of the audit_log_export.completed Event.
+type AuditLogExportData struct {
+	// The Audit Log Export's unique identifier.
+	ID string `json:"id"`
+
+	// The state of the Audit Log Export, eg. "ready".
+	State string `json:"state"`
+
+	// The URL to download the exported Audit Log events from.
+	URL string `json:"url"`
+
+	// The timestamp of when the Audit Log Export was created.
+	CreatedAt string `json:"created_at"`
+
+	// The timestamp of when the Audit Log Export was updated.
+	UpdatedAt string `json:"updated_at"`
+}
+
+// DirectoryUserEmail contains an email address of a Directory User.
+type DirectoryUserEmail struct {
+	// Whether this is the primary email address of the Directory User.
+	Primary bool `json:"primary"`
+
+	// The email address.
+	Value string `json:"value"`
+
+	// The type of email address, eg. "work".
+	Type string `json:"type"`
+}
+
+// DirectoryUserData is the payload of the dsync.user.updated and
+// dsync.user.deleted Events.
+type DirectoryUserData struct {
+	// The Directory User's unique identifier.
+	ID string `json:"id"`
+
+	// The Directory User's unique identifier assigned by the Directory
+	// Provider.
+	IdpID string `json:"idp_id"`
+
+	// The ID of the Directory the Directory User belongs to.
+	DirectoryID string `json:"directory_id"`
+
+	// The ID of the Organization in which the Directory resides.
+	OrganizationID string `json:"organization_id"`
+
+	// The Directory User's username.
+	Username string `json:"username"`
+
+	// The Directory User's email addresses.
+	Emails []DirectoryUserEmail `json:"emails"`
+
+	// The Directory User's first name.
+	FirstName string `json:"first_name"`
+
+	// The Directory User's last name.
+	LastName string `json:"last_name"`
+
+	// The state of the Directory User, eg. "active" or "inactive".
+	State string `json:"state"`
+
+	// The Directory User's raw attributes, as sent by the Directory Provider.
+	RawAttributes json.RawMessage `json:"raw_attributes"`
+
+	// The Directory User's custom attributes.
+	CustomAttributes json.RawMessage `json:"custom_attributes"`
+
+	// The previous values of the attributes changed by a dsync.user.updated
+	// Event, keyed by attribute. Empty for other Events.
+	PreviousAttributes map[string]interface{} `json:"previous_attributes,omitempty"`
+
+	// The timestamp of when the Directory User was created.
+	CreatedAt string `json:"created_at"`
+
+	// The timestamp of when the Directory User was updated.
+	UpdatedAt string `json:"updated_at"`
+}
+
+// payloadDecoders maps the Event types to the function decoding their data into
+// a typed payload.
+var payloadDecoders = map[string]func(json.RawMessage) (interface{}, error){
+	OrganizationMembershipCreated: decodeOrganizationMembership,
+	OrganizationMembershipUpdated: decodeOrganizationMembership,
+	OrganizationMembershipDeleted: decodeOrganizationMembership,
+	AuditLogExportCompleted:       decodeAuditLogExport,
+	DirectoryUserUpdated:          decodeDirectoryUser,
+	DirectoryUserDeleted:          decodeDirectoryUser,
+}
+
+// ParseEvent decodes the data of an Event into the typed payload of its type,
+// eg. OrganizationMembershipData for organization_membership.created Events.
+//
+// The data of Event types without a typed payload is returned as is, as a
+// json.RawMessage.
+func ParseEvent(event Event) (interface{}, error) {
+	decode, ok := payloadDecoders[event.Event]
+	if !ok {
+		return event.Data, nil
+	}
+
+	payload, err := decode(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s event data: %w", event.Event, err)
+	}
+	return payload, nil
+}
+
+func decodeOrganizationMembership(data json.RawMessage) (interface{}, error) {
+	var membership OrganizationMembershipData
+	err := json.Unmarshal(data, &membership)
+	return membership, err
+}
+
+func decodeAuditLogExport(data json.RawMessage) (interface{}, error) {
+	var export AuditLogExportData
+	err := json.Unmarshal(data, &export)
+	return export, err
+}
+
+func decodeDirectoryUser(data json.RawMessage) (interface{}, error) {
+	var user DirectoryUserData
+	err := json.Unmarshal(data, &user)
+	return user, err
+}
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuditLogExportCompletedEvent(t *testing.T) {
+	var event Event
+	err := json.Unmarshal([]byte(`{
+		"id": "event_123",
+		"event": "audit_log_export.completed",
+		"data": {"id": "audit_log_export_123", "state": "ready", "url": "https://exports.workos.com/export.csv"}
+	}`), &event)
+	require.NoError(t, err)
+
+	payload, err := ParseEvent(event)
+	require.NoError(t, err)
+
+	export, ok := payload.(AuditLogExportData)
+	require.True(t, ok)
+	require.Equal(t, "https://exports.workos.com/export.csv", export.URL)
+}
+
+func TestParseEvent(t *testing.T) {
+	membershipData := json.RawMessage(`{
+		"id": "om_123",
+		"user_id": "user_123",
+		"organization_id": "org_123",
+		"role": {"slug": "admin"},
+		"status": "active",
+		"created_at": "2021-06-25T19:07:33.155Z",
+		"updated_at": "2021-06-25T19:07:33.155Z"
+	}`)
+
+	membership := OrganizationMembershipData{
+		ID:             "om_123",
+		UserID:         "user_123",
+		OrganizationID: "org_123",
+		Role:           OrganizationMembershipRole{Slug: "admin"},
+		Status:         "active",
+		CreatedAt:      "2021-06-25T19:07:33.155Z",
+		UpdatedAt:      "2021-06-25T19:07:33.155Z",
+	}
+
+	tests := []struct {
+		scenario string
+		event    Event
+		expected interface{}
+		err      bool
+	}{
+		{
+			scenario: "organization_membership.created returns the membership",
+			event:    Event{Event: OrganizationMembershipCreated, Data: membershipData},
+			expected: membership,
+		},
+		{
+			scenario: "organization_membership.updated returns the membership",
+			event:    Event{Event: OrganizationMembershipUpdated, Data: membershipData},
+			expected: membership,
+		},
+		{
+			scenario: "organization_membership.deleted returns the membership",
+			event:    Event{Event: OrganizationMembershipDeleted, Data: membershipData},
+			expected: membership,
+		},
+		{
+			scenario: "audit_log_export.completed returns the export",
+			event: Event{
+				Event: AuditLogExportCompleted,
+				Data: json.RawMessage(`{
+					"object": "audit_log_export",
+					"id": "audit_log_export_123",
+					"state": "ready",
+					"url": "https://exports.workos.com/audit_log_export_123.csv",
+					"created_at": "2021-06-25T19:07:33.155Z",
+					"updated_at": "2021-06-25T19:08:33.155Z"
+				}`),
+			},
+			expected: AuditLogExportData{
+				ID:        "audit_log_export_123",
+				State:     "ready",
+				URL:       "https://exports.workos.com/audit_log_export_123.csv",
+				CreatedAt: "2021-06-25T19:07:33.155Z",
+				UpdatedAt: "2021-06-25T19:08:33.155Z",
+			},
+		},
+		{
+			scenario: "Directory user updated event returns DirectoryUserData with previous attributes",
+			event: Event{
+				Event: DirectoryUserUpdated,
+				Data: json.RawMessage(`{
+					"id": "directory_user_123",
+					"idp_id": "idp_123",
+					"directory_id": "directory_123",
+					"organization_id": "org_123",
+					"username": "marcelina@foo-corp.com",
+					"emails": [{"primary": true, "type": "work", "value": "marcelina@foo-corp.com"}],
+					"first_name": "Marcelina",
+					"last_name": "Hoeger",
+					"state": "active",
+					"raw_attributes": {"department": "Engineering"},
+					"custom_attributes": {"department": "Engineering"},
+					"previous_attributes": {"last_name": "Davis"},
+					"created_at": "2021-06-25T19:07:33.155Z",
+					"updated_at": "2021-06-26T19:07:33.155Z"
+				}`),
+			},
+			expected: DirectoryUserData{
+				ID:             "directory_user_123",
+				IdpID:          "idp_123",
+				DirectoryID:    "directory_123",
+				OrganizationID: "org_123",
+				Username:       "marcelina@foo-corp.com",
+				Emails: []DirectoryUserEmail{
+					{Primary: true, Type: "work", Value: "marcelina@foo-corp.com"},
+				},
+				FirstName:          "Marcelina",
+				LastName:           "Hoeger",
+				State:              "active",
+				RawAttributes:      json.RawMessage(`{"department": "Engineering"}`),
+				CustomAttributes:   json.RawMessage(`{"department": "Engineering"}`),
+				PreviousAttributes: map[string]interface{}{"last_name": "Davis"},
+				CreatedAt:          "2021-06-25T19:07:33.155Z",
+				UpdatedAt:          "2021-06-26T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Directory user deleted event returns DirectoryUserData",
+			event: Event{
+				Event: DirectoryUserDeleted,
+				Data: json.RawMessage(`{
+					"id": "directory_user_123",
+					"directory_id": "directory_123",
+					"organization_id": "org_123",
+					"emails": [],
+					"state": "inactive",
+					"created_at": "2021-06-25T19:07:33.155Z",
+					"updated_at": "2021-06-27T19:07:33.155Z"
+				}`),
+			},
+			expected: DirectoryUserData{
+				ID:             "directory_user_123",
+				DirectoryID:    "directory_123",
+				OrganizationID: "org_123",
+				Emails:         []DirectoryUserEmail{},
+				State:          "inactive",
+				CreatedAt:      "2021-06-25T19:07:33.155Z",
+				UpdatedAt:      "2021-06-27T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Malformed data returns an error",
+			event:    Event{Event: OrganizationMembershipCreated, Data: json.RawMessage(`[]`)},
+			err:      true,
+		},
+		{
+			scenario: "Event without typed payload returns its raw data",
+			event:    Event{Event: "connection.activated", Data: json.RawMessage(`{"foo":"bar"}`)},
+			expected: json.RawMessage(`{"foo":"bar"}`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			payload, err := ParseEvent(test.event)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, payload)
+		})
+	}
+}
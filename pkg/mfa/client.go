@@ -7,11 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
+	"github.com/workos/workos-go/v3/pkg/common"
 
 	"github.com/workos/workos-go/v3/internal/workos"
 )
@@ -33,18 +34,41 @@ type Client struct {
 	// Defaults to http.Client.
 	HTTPClient *http.Client
 
-	// The endpoint to WorkOS API. Defaults to https://api.workos.com.
+	// The endpoint to WorkOS API. Defaults to the WORKOS_API_ENDPOINT
+	// environment variable when set, and to https://api.workos.com otherwise.
 	Endpoint string
 
 	// The function used to encode in JSON. Defaults to json.Marshal.
 	JSONEncode func(v interface{}) ([]byte, error)
 
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
 func (c *Client) init() {
 	if c.Endpoint == "" {
-		c.Endpoint = "https://api.workos.com"
+		c.Endpoint = workos.DefaultEndpoint()
 	}
 	c.Endpoint = strings.TrimSuffix(c.Endpoint, "/")
 
@@ -57,6 +81,21 @@ func (c *Client) init() {
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // Type represents the type of Authentication Factor
 type FactorType string
 
@@ -106,9 +145,50 @@ type Factor struct {
 }
 
 type TOTPDetails struct {
+	// The issuer shown by authenticator apps, eg. the name of your application.
+	Issuer string `json:"issuer"`
+
+	// The user shown by authenticator apps, eg. their email address.
+	User string `json:"user"`
+
+	// A data URL of a QR code image encoding URI, to be scanned by
+	// authenticator apps.
 	QRCode string `json:"qr_code"`
+
+	// The base32 encoded secret, for users entering it manually.
 	Secret string `json:"secret"`
-	URI    string `json:"uri"`
+
+	// The otpauth:// provisioning URI of the factor.
+	URI string `json:"uri"`
+}
+
+// ProvisioningURI returns the otpauth:// URI adding the factor to an
+// authenticator app. It is the URI returned by the API, or the URI built from
+// the Issuer, User and Secret when the API did not return one.
+func (d TOTPDetails) ProvisioningURI() string {
+	if d.URI != "" {
+		return d.URI
+	}
+	if d.Secret == "" {
+		return ""
+	}
+
+	label := otpauthEscape(d.User)
+	if d.Issuer != "" {
+		label = otpauthEscape(d.Issuer) + ":" + label
+	}
+
+	uri := "otpauth://totp/" + label + "?secret=" + otpauthEscape(d.Secret)
+	if d.Issuer != "" {
+		uri += "&issuer=" + otpauthEscape(d.Issuer)
+	}
+	return uri
+}
+
+// otpauthEscape escapes a component of an otpauth:// URI. Spaces are encoded as
+// %20 rather than +, which authenticator apps do not all decode.
+func otpauthEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
 }
 
 type SMSDetails struct {
@@ -217,13 +297,13 @@ func (c *Client) EnrollFactor(
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return Factor{}, err
 	}
 	defer resp.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(resp); err != nil {
+	if err = c.tryGetHTTPError(resp); err != nil {
 		return Factor{}, err
 	}
 
@@ -258,13 +338,13 @@ func (c *Client) ChallengeFactor(
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return Challenge{}, err
 	}
 	defer resp.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(resp); err != nil {
+	if err = c.tryGetHTTPError(resp); err != nil {
 		return Challenge{}, err
 	}
 
@@ -289,7 +369,9 @@ func (r VerificationResponseError) Error() string {
 	return fmt.Sprintf("mfa verification failed: %s (code: %s)", r.Message, r.Code)
 }
 
-// Verifies the one time password provided by the end-user.
+// Verifies the one time password provided by the end-user. A code that WorkOS
+// rejects is reported with a VerificationResponseError, while failed requests
+// are passed through the ErrorHandler like those of the other methods.
 func (c *Client) VerifyChallenge(
 	ctx context.Context,
 	opts VerifyChallengeOpts,
@@ -313,12 +395,16 @@ func (c *Client) VerifyChallenge(
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return VerifyChallengeResponse{}, err
 	}
 	defer resp.Body.Close()
 
+	if err = c.tryGetHTTPError(resp); err != nil {
+		return VerifyChallengeResponse{}, err
+	}
+
 	var body RawVerifyChallengeResponse
 	dec := json.NewDecoder(resp.Body)
 	err = dec.Decode(&body)
@@ -358,13 +444,13 @@ func (c *Client) DeleteFactor(
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
 
 // Retrieves an authentication factor.
@@ -383,13 +469,13 @@ func (c *Client) GetFactor(
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Factor{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Factor{}, err
 	}
 
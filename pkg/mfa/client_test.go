@@ -3,6 +3,7 @@ package mfa
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -162,6 +163,94 @@ func TestEnrollFactor(t *testing.T) {
 	}
 }
 
+func TestTOTPDetailsProvisioningURI(t *testing.T) {
+	tests := []struct {
+		scenario string
+		details  TOTPDetails
+		expected string
+	}{
+		{
+			scenario: "URI returned by the API",
+			details: TOTPDetails{
+				Issuer: "WorkOS",
+				User:   "some_user",
+				Secret: "JBSWY3DPEHPK3PXP",
+				URI:    "otpauth://totp/WorkOS:some_user?secret=JBSWY3DPEHPK3PXP&issuer=WorkOS",
+			},
+			expected: "otpauth://totp/WorkOS:some_user?secret=JBSWY3DPEHPK3PXP&issuer=WorkOS",
+		},
+		{
+			scenario: "URI built from the secret",
+			details: TOTPDetails{
+				Issuer: "Foo Corp",
+				User:   "marcelina@foo-corp.com",
+				Secret: "JBSWY3DPEHPK3PXP",
+			},
+			expected: "otpauth://totp/Foo%20Corp:marcelina%40foo-corp.com?secret=JBSWY3DPEHPK3PXP&issuer=Foo%20Corp",
+		},
+		{
+			scenario: "URI built without issuer",
+			details: TOTPDetails{
+				User:   "marcelina@foo-corp.com",
+				Secret: "JBSWY3DPEHPK3PXP",
+			},
+			expected: "otpauth://totp/marcelina%40foo-corp.com?secret=JBSWY3DPEHPK3PXP",
+		},
+		{
+			scenario: "No URI without secret",
+			details:  TOTPDetails{Issuer: "WorkOS", User: "some_user"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			require.Equal(t, test.expected, test.details.ProvisioningURI())
+		})
+	}
+}
+
+func TestEnrollFactorDecodesTOTPDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"object": "authentication_factor",
+			"id": "auth_factor_test123",
+			"created_at": "2022-02-17T22:39:26.616Z",
+			"updated_at": "2022-02-17T22:39:26.616Z",
+			"type": "totp",
+			"totp": {
+				"issuer": "WorkOS",
+				"user": "some_user",
+				"qr_code": "data:image/png;base64,iVBORw0KGgo=",
+				"secret": "JBSWY3DPEHPK3PXP",
+				"uri": "otpauth://totp/WorkOS:some_user?secret=JBSWY3DPEHPK3PXP&issuer=WorkOS"
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		APIKey:     "test",
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+	}
+
+	factor, err := client.EnrollFactor(context.Background(), EnrollFactorOpts{
+		Type:       TOTP,
+		TOTPIssuer: "WorkOS",
+		TOTPUser:   "some_user",
+	})
+	require.NoError(t, err)
+	require.Equal(t, TOTPDetails{
+		Issuer: "WorkOS",
+		User:   "some_user",
+		QRCode: "data:image/png;base64,iVBORw0KGgo=",
+		Secret: "JBSWY3DPEHPK3PXP",
+		URI:    "otpauth://totp/WorkOS:some_user?secret=JBSWY3DPEHPK3PXP&issuer=WorkOS",
+	}, factor.TOTP)
+	require.Equal(t, factor.TOTP.URI, factor.TOTP.ProvisioningURI())
+}
+
 func enrollFactorTestHandler(w http.ResponseWriter, r *http.Request) {
 	auth := r.Header.Get("Authorization")
 	if auth != "Bearer test" {
@@ -416,6 +505,31 @@ func TestVerifyChallengeError(t *testing.T) {
 	}
 }
 
+func TestVerifyChallengeErrorHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(verifyChallengeTestHandler))
+	defer server.Close()
+
+	handled := errors.New("handled")
+	var status int
+
+	client := &Client{
+		APIKey:     "invalid",
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+		ErrorHandler: func(res *http.Response, err error) error {
+			status = res.StatusCode
+			return handled
+		},
+	}
+
+	_, err := client.VerifyChallenge(context.Background(), VerifyChallengeOpts{
+		ChallengeID: "auth_challenge_test123",
+		Code:        "0000000",
+	})
+	require.Equal(t, handled, err)
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
 func verifyChallengeErrorTestHandler(w http.ResponseWriter, r *http.Request) {
 	auth := r.Header.Get("Authorization")
 	if auth != "Bearer test" {
@@ -6,9 +6,7 @@ import (
 
 // DefaultClient is the client used by SetAPIKey and mfa functions.
 var (
-	DefaultClient = &Client{
-		Endpoint: "https://api.workos.com",
-	}
+	DefaultClient = &Client{}
 )
 
 // SetAPIKey sets the WorkOS API key for mfa requests.
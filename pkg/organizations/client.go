@@ -9,8 +9,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
-
 	"github.com/google/go-querystring/query"
 	"github.com/workos/workos-go/v3/internal/workos"
 	"github.com/workos/workos-go/v3/pkg/common"
@@ -37,12 +35,35 @@ type Client struct {
 	// Defaults to http.Client.
 	HTTPClient *http.Client
 
-	// The endpoint to WorkOS API. Defaults to https://api.workos.com.
+	// The endpoint to WorkOS API. Defaults to the WORKOS_API_ENDPOINT
+	// environment variable when set, and to https://api.workos.com otherwise.
 	Endpoint string
 
 	// The function used to encode in JSON. Defaults to json.Marshal.
 	JSONEncode func(v interface{}) ([]byte, error)
 
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
@@ -52,7 +73,7 @@ func (c *Client) init() {
 	}
 
 	if c.Endpoint == "" {
-		c.Endpoint = "https://api.workos.com"
+		c.Endpoint = workos.DefaultEndpoint()
 	}
 
 	if c.JSONEncode == nil {
@@ -60,6 +81,21 @@ func (c *Client) init() {
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // OrganizationDomain contains data about an Organization's Domains.
 type OrganizationDomain struct {
 	// The Organization Domain's unique identifier.
@@ -169,7 +205,8 @@ func (c *Client) GetOrganization(
 		c.Endpoint,
 		opts.Organization,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -178,18 +215,17 @@ func (c *Client) GetOrganization(
 		return Organization{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Organization{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Organization{}, err
 	}
 
@@ -207,7 +243,8 @@ func (c *Client) ListOrganizations(
 	c.once.Do(c.init)
 
 	endpoint := fmt.Sprintf("%s/organizations", c.Endpoint)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -216,7 +253,6 @@ func (c *Client) ListOrganizations(
 		return ListOrganizationsResponse{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -232,13 +268,13 @@ func (c *Client) ListOrganizations(
 
 	req.URL.RawQuery = q.Encode()
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListOrganizationsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListOrganizationsResponse{}, err
 	}
 
@@ -258,23 +294,22 @@ func (c *Client) CreateOrganization(ctx context.Context, opts CreateOrganization
 	}
 
 	endpoint := fmt.Sprintf("%s/organizations", c.Endpoint)
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return Organization{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Idempotency-Key", opts.IdempotencyKey)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Organization{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Organization{}, err
 	}
 
@@ -309,22 +344,21 @@ func (c *Client) UpdateOrganization(ctx context.Context, opts UpdateOrganization
 	}
 
 	endpoint := fmt.Sprintf("%s/organizations/%s", c.Endpoint, opts.Organization)
-	req, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return Organization{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Organization{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Organization{}, err
 	}
 
@@ -352,7 +386,8 @@ func (c *Client) DeleteOrganization(
 		c.Endpoint,
 		opts.Organization,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodDelete,
 		endpoint,
 		nil,
@@ -361,16 +396,15 @@ func (c *Client) DeleteOrganization(
 		return err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/workos/workos-go/v3/internal/workos"
 	"github.com/workos/workos-go/v3/pkg/common"
 )
 
@@ -455,3 +457,27 @@ func updateOrganizationTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(body)
 }
+
+func TestClientEndpointFromEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getOrganizationTestHandler))
+	defer server.Close()
+
+	os.Setenv(workos.EndpointEnvVar, server.URL+"/")
+	defer os.Unsetenv(workos.EndpointEnvVar)
+
+	client := &Client{APIKey: "test", HTTPClient: server.Client()}
+	_, err := client.GetOrganization(context.Background(), GetOrganizationOpts{
+		Organization: "organization_id",
+	})
+	require.NoError(t, err)
+	require.Equal(t, server.URL, client.Endpoint)
+
+	explicit := &Client{Endpoint: "https://sandbox.workos.test"}
+	explicit.init()
+	require.Equal(t, "https://sandbox.workos.test", explicit.Endpoint)
+
+	os.Unsetenv(workos.EndpointEnvVar)
+	production := &Client{}
+	production.init()
+	require.Equal(t, workos.ProductionEndpoint, production.Endpoint)
+}
@@ -7,9 +7,7 @@ import (
 
 // DefaultClient is the client used by SetAPIKey and Organizations functions.
 var (
-	DefaultClient = &Client{
-		Endpoint: "https://api.workos.com",
-	}
+	DefaultClient = &Client{}
 )
 
 // SetAPIKey sets the WorkOS API key for Organizations requests.
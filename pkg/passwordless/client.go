@@ -9,7 +9,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
+	"github.com/workos/workos-go/v3/pkg/common"
 
 	"github.com/workos/workos-go/v3/internal/workos"
 )
@@ -29,12 +29,35 @@ type Client struct {
 
 	// The endpoint to WorkOS API.
 	//
-	// Defaults to https://api.workos.com.
+	// Defaults to the WORKOS_API_ENDPOINT environment variable when set, and to
+	// https://api.workos.com otherwise.
 	Endpoint string
 
 	// The function used to encode in JSON. Defaults to json.Marshal.
 	JSONEncode func(v interface{}) ([]byte, error)
 
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
@@ -44,7 +67,7 @@ func (c *Client) init() {
 	}
 
 	if c.Endpoint == "" {
-		c.Endpoint = "https://api.workos.com"
+		c.Endpoint = workos.DefaultEndpoint()
 	}
 
 	if c.JSONEncode == nil {
@@ -52,6 +75,21 @@ func (c *Client) init() {
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // PasswordlessSession contains data about a WorkOS Passwordless Session.
 type PasswordlessSession struct {
 	// The Passwordless Session's unique identifier.
@@ -113,22 +151,21 @@ func (c *Client) CreateSession(ctx context.Context, opts CreateSessionOpts) (Pas
 	}
 
 	endpoint := fmt.Sprintf("%s/passwordless/sessions", c.Endpoint)
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return PasswordlessSession{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return PasswordlessSession{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return PasswordlessSession{}, err
 	}
 
@@ -161,20 +198,19 @@ func (c *Client) SendSession(
 		c.Endpoint,
 		opts.SessionID,
 	)
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
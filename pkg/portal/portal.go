@@ -7,9 +7,7 @@ import (
 
 // DefaultClient is the client used by SetAPIKey and Admin Portal functions.
 var (
-	DefaultClient = &Client{
-		Endpoint: "https://api.workos.com",
-	}
+	DefaultClient = &Client{}
 )
 
 // SetAPIKey sets the WorkOS API key for Admin Portal requests.
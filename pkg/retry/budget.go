@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget is a token bucket limiting the number of retries, so that a
+// widespread outage does not multiply the load on WorkOS with the retries of
+// every request. Each retry takes a token from the budget and tokens are added
+// back over time. A Budget is safe for concurrent use and can be shared by the
+// Transports of several clients.
+//
+// Budgets are created with NewBudget. The zero value is an empty Budget that is
+// never refilled: rate limited requests are not retried.
+type Budget struct {
+	capacity       float64
+	refillInterval time.Duration
+	now            func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewBudget returns a full Budget of capacity retries, to which a retry is
+// added back every refillInterval, up to capacity. The budget is never refilled
+// when refillInterval is zero.
+func NewBudget(capacity int, refillInterval time.Duration) *Budget {
+	return &Budget{
+		capacity:       float64(capacity),
+		refillInterval: refillInterval,
+		now:            time.Now,
+		tokens:         float64(capacity),
+	}
+}
+
+// take takes a retry from the budget and reports whether there was one left.
+// A nil Budget is unlimited.
+func (b *Budget) take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.now != nil {
+		now = b.now()
+	}
+	if b.refillInterval > 0 && !b.last.IsZero() {
+		b.tokens += float64(now.Sub(b.last)) / float64(b.refillInterval)
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
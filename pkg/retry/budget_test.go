@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportBudget(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{
+		MaxRetries: 3,
+		Backoff:    time.Millisecond,
+		Budget:     NewBudget(2, 0),
+	}}
+
+	// The first request drains the budget after two of its three retries.
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, 3, requests)
+
+	// The following requests fail fast, without being retried.
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+
+		res, err = client.Get(server.URL)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.True(t, time.Since(start) < time.Second)
+	}
+	require.Equal(t, 6, requests)
+}
+
+func TestBudgetRefill(t *testing.T) {
+	now := time.Date(2021, 6, 25, 19, 7, 33, 0, time.UTC)
+
+	budget := NewBudget(2, time.Second)
+	budget.now = func() time.Time { return now }
+
+	require.True(t, budget.take())
+	require.True(t, budget.take())
+	require.False(t, budget.take())
+
+	now = now.Add(500 * time.Millisecond)
+	require.False(t, budget.take())
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, budget.take())
+	require.False(t, budget.take())
+
+	// The budget never holds more than its capacity.
+	now = now.Add(time.Hour)
+	require.True(t, budget.take())
+	require.True(t, budget.take())
+	require.False(t, budget.take())
+
+	var unlimited *Budget
+	require.True(t, unlimited.take())
+}
+
+func TestTransportZeroBudget(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{Backoff: time.Millisecond, Budget: &Budget{}}}
+
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, 1, requests)
+}
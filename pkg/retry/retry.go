@@ -0,0 +1,207 @@
+// Package `retry` provides an http.RoundTripper retrying the requests rate
+// limited by the WorkOS API.
+//
+// Example:
+//
+//	func main() {
+//	    client := usermanagement.NewClient("my_api_key")
+//	    client.HTTPClient = &http.Client{
+//	        Timeout:   10 * time.Second,
+//	        Transport: &retry.Transport{},
+//	    }
+//	}
+package retry
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/workos/workos-go/v3/internal/workos"
+	"github.com/workos/workos-go/v3/pkg/workos_errors"
+)
+
+// DefaultMaxRetries is the number of times a rate limited request is retried
+// when the Transport does not configure it.
+const DefaultMaxRetries = 3
+
+// DefaultBackoff is the delay before the first retry of a rate limited request
+// without Retry-After when the Transport does not configure it.
+const DefaultBackoff = time.Second
+
+// Transport is an http.RoundTripper retrying the requests rejected with 429
+// Too Many Requests, after the delay given by the response or with an
+// exponential backoff.
+//
+// The retries can be limited across requests with a Budget.
+//
+// While a request is backing off, the requests sharing its rate limit key wait
+// for the backoff to end before being sent.
+//
+// Each attempt at sending a request of a WorkOS client is reported to the
+// client's OnRequestComplete hook along with its attempt number.
+type Transport struct {
+	// The transport sending the requests. Defaults to http.DefaultTransport.
+	//
+	// OPTIONAL.
+	Base http.RoundTripper
+
+	// The maximum number of times a rate limited request is retried. Defaults
+	// to DefaultMaxRetries.
+	//
+	// OPTIONAL.
+	MaxRetries int
+
+	// The delay before the first retry of a rate limited response without
+	// Retry-After, doubled for every following retry. Defaults to
+	// DefaultBackoff.
+	//
+	// OPTIONAL.
+	Backoff time.Duration
+
+	// A function returning the key under which the backoff state of a request
+	// is kept, eg. the ID of the Organization the request is scoped to, so
+	// that a rate limited Organization does not slow down the others. All the
+	// requests share the same backoff state when nil.
+	//
+	// OPTIONAL.
+	RateLimitKeyFunc func(*http.Request) string
+
+	// The budget every retry is taken from. Once it is exhausted, rate
+	// limited requests fail fast: their response is returned without being
+	// retried. Share a Budget between the Transports of several clients to
+	// limit their retries as a whole. Retries are only limited by MaxRetries
+	// when nil.
+	//
+	// OPTIONAL.
+	Budget *Budget
+
+	mu           sync.Mutex
+	backoffUntil map[string]time.Time
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := ""
+	if t.RateLimitKeyFunc != nil {
+		key = t.RateLimitKeyFunc(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.waitBackoff(req, key); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		res, err := t.base().RoundTrip(attemptReq)
+		workos.ReportAttempt(req.Context(), attempt+1, res, time.Since(start))
+
+		if err != nil || res.StatusCode != http.StatusTooManyRequests || !t.canRetry(req, attempt) || !t.Budget.take() {
+			return res, err
+		}
+
+		t.setBackoff(key, t.retryDelay(res, attempt))
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// canRetry reports whether the request can be sent again after the given
+// attempt, which requires its body to be replayable.
+func (t *Transport) canRetry(req *http.Request, attempt int) bool {
+	maxRetries := t.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	return attempt < maxRetries && (req.Body == nil || req.GetBody != nil)
+}
+
+// retryDelay returns the delay before retrying the rate limited response,
+// which is consumed.
+func (t *Transport) retryDelay(res *http.Response, attempt int) time.Duration {
+	defer res.Body.Close()
+
+	var delay time.Duration
+	if httpErr, ok := workos_errors.TryGetHTTPError(res).(workos_errors.HTTPError); ok {
+		delay = httpErr.RetryAfter
+	}
+	io.Copy(ioutil.Discard, res.Body)
+
+	if delay > 0 {
+		return delay
+	}
+
+	backoff := t.Backoff
+	if backoff == 0 {
+		backoff = DefaultBackoff
+	}
+	return backoff << uint(attempt)
+}
+
+// waitBackoff waits for the backoff of the given key to end, or for the
+// request's context to be done.
+func (t *Transport) waitBackoff(req *http.Request, key string) error {
+	t.mu.Lock()
+	until := t.backoffUntil[key]
+	t.mu.Unlock()
+
+	delay := time.Until(until)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// setBackoff makes the requests of the given key wait for delay, unless they
+// already wait longer.
+func (t *Transport) setBackoff(key string, delay time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.backoffUntil == nil {
+		t.backoffUntil = make(map[string]time.Time)
+	}
+
+	until := time.Now().Add(delay)
+	if until.After(t.backoffUntil[key]) {
+		t.backoffUntil[key] = until
+	}
+}
+
+// rewind returns the request to send for the given attempt, with a fresh body
+// for the retries.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
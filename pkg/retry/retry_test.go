@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportRetriesRateLimitedRequests(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		attempt := len(bodies)
+		mu.Unlock()
+
+		if attempt < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{Backoff: time.Millisecond}}
+
+	res, err := client.Post(server.URL, "application/json", bytes.NewBufferString(`{"name":"Foo Corp"}`))
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.Equal(t, []string{`{"name":"Foo Corp"}`, `{"name":"Foo Corp"}`, `{"name":"Foo Corp"}`}, bodies)
+}
+
+func TestTransportGivesUpAfterMaxRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{MaxRetries: 2, Backoff: time.Millisecond}}
+
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, 3, requests)
+}
+
+func TestTransportRateLimitKeyFunc(t *testing.T) {
+	newServer := func(requests map[string]int, mu *sync.Mutex) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			organizationID := r.URL.Query().Get("organization_id")
+
+			mu.Lock()
+			requests[organizationID]++
+			mu.Unlock()
+
+			if organizationID == "org_noisy" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+
+	get := func(client *http.Client, url string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	t.Run("Backoff state is kept per key", func(t *testing.T) {
+		var mu sync.Mutex
+		requests := make(map[string]int)
+		server := newServer(requests, &mu)
+		defer server.Close()
+
+		client := &http.Client{Transport: &Transport{
+			Backoff: time.Hour,
+			RateLimitKeyFunc: func(r *http.Request) string {
+				return r.URL.Query().Get("organization_id")
+			},
+		}}
+
+		err := get(client, server.URL+"?organization_id=org_noisy")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		err = get(client, server.URL+"?organization_id=org_quiet")
+		require.NoError(t, err)
+
+		err = get(client, server.URL+"?organization_id=org_noisy")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		require.Equal(t, map[string]int{"org_noisy": 1, "org_quiet": 1}, requests)
+	})
+
+	t.Run("Backoff state is shared without key", func(t *testing.T) {
+		var mu sync.Mutex
+		requests := make(map[string]int)
+		server := newServer(requests, &mu)
+		defer server.Close()
+
+		client := &http.Client{Transport: &Transport{Backoff: time.Hour}}
+
+		err := get(client, server.URL+"?organization_id=org_noisy")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		err = get(client, server.URL+"?organization_id=org_quiet")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		require.Equal(t, map[string]int{"org_noisy": 1}, requests)
+	})
+}
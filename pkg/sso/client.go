@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/go-querystring/query"
-	"github.com/workos/workos-go/v3/pkg/workos_errors"
 	"net/http"
 	"net/url"
 	"strings"
@@ -83,7 +82,8 @@ type Client struct {
 
 	// The endpoint to WorkOS API.
 	//
-	// Defaults to https://api.workos.com.
+	// Defaults to the WORKOS_API_ENDPOINT environment variable when set, and to
+	// https://api.workos.com otherwise.
 	Endpoint string
 
 	// The http.Client that is used to send request to WorkOS.
@@ -94,12 +94,34 @@ type Client struct {
 	// The function used to encode in JSON. Defaults to json.Marshal.
 	JSONEncode func(v interface{}) ([]byte, error)
 
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
 	once sync.Once
 }
 
 func (c *Client) init() {
 	if c.Endpoint == "" {
-		c.Endpoint = "https://api.workos.com"
+		c.Endpoint = workos.DefaultEndpoint()
 	}
 	c.Endpoint = strings.TrimSuffix(c.Endpoint, "/")
 
@@ -112,6 +134,21 @@ func (c *Client) init() {
 	}
 }
 
+// do sends the request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // GetLoginHandler returns an http.Handler that redirects client to the appropriate
 // login provider.
 func (c *Client) GetLoginHandler(opts GetAuthorizationURLOpts) http.Handler {
@@ -132,6 +169,9 @@ func (c *Client) GetLoginHandler(opts GetAuthorizationURLOpts) http.Handler {
 type GetAuthorizationURLOpts struct {
 	// Deprecated: Please use `Organization` parameter instead.
 	// The app/company domain without without protocol (eg. example.com).
+	//
+	// Existing domain-based integrations can resolve the Organization to use
+	// with OrganizationIDForDomain.
 	Domain string
 
 	// Domain hint that will be passed as a parameter to the IdP login page.
@@ -185,7 +225,7 @@ func (c *Client) GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, er
 	}
 	if opts.Domain != "" {
 		query.Set("domain", opts.Domain)
-		fmt.Println("The `domain` parameter for `getAuthorizationURL` is deprecated. Please use `organization` instead.")
+		fmt.Println("The `domain` parameter for `getAuthorizationURL` is deprecated. Please use `organization` instead, see `OrganizationIDForDomain`.")
 	}
 	if opts.DomainHint != "" {
 		query.Set("domain_hint", opts.DomainHint)
@@ -273,7 +313,8 @@ func (c *Client) GetProfileAndToken(ctx context.Context, opts GetProfileAndToken
 	form.Set("grant_type", "authorization_code")
 	form.Set("code", opts.Code)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.Endpoint+"/sso/token",
 		strings.NewReader(form.Encode()),
@@ -281,18 +322,17 @@ func (c *Client) GetProfileAndToken(ctx context.Context, opts GetProfileAndToken
 	if err != nil {
 		return ProfileAndToken{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ProfileAndToken{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ProfileAndToken{}, err
 	}
 
@@ -315,7 +355,8 @@ type GetProfileOpts struct {
 func (c *Client) GetProfile(ctx context.Context, opts GetProfileOpts) (Profile, error) {
 	c.once.Do(c.init)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		c.Endpoint+"/sso/profile",
 		nil,
@@ -323,17 +364,16 @@ func (c *Client) GetProfile(ctx context.Context, opts GetProfileOpts) (Profile,
 	if err != nil {
 		return Profile{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+opts.AccessToken)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Profile{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Profile{}, err
 	}
 
@@ -405,6 +445,15 @@ type Connection struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+// DomainStrings returns the domain names of the Connection.
+func (c Connection) DomainStrings() []string {
+	domains := make([]string, 0, len(c.Domains))
+	for _, d := range c.Domains {
+		domains = append(domains, d.Domain)
+	}
+	return domains
+}
+
 // GetConnectionOpts contains the options to request details for a Connection.
 type GetConnectionOpts struct {
 	// Connection unique identifier.
@@ -423,7 +472,8 @@ func (c *Client) GetConnection(
 		c.Endpoint,
 		opts.Connection,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -432,18 +482,17 @@ func (c *Client) GetConnection(
 		return Connection{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Connection{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Connection{}, err
 	}
 
@@ -495,7 +544,8 @@ func (c *Client) ListConnections(
 	c.once.Do(c.init)
 
 	endpoint := fmt.Sprintf("%s/connections", c.Endpoint)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -504,7 +554,6 @@ func (c *Client) ListConnections(
 		return ListConnectionsResponse{}, err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
@@ -518,13 +567,13 @@ func (c *Client) ListConnections(
 	}
 
 	req.URL.RawQuery = v.Encode()
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListConnectionsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListConnectionsResponse{}, err
 	}
 
@@ -552,7 +601,8 @@ func (c *Client) DeleteConnection(
 		c.Endpoint,
 		opts.Connection,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodDelete,
 		endpoint,
 		nil,
@@ -561,16 +611,44 @@ func (c *Client) DeleteConnection(
 		return err
 	}
 
-	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
+}
+
+// ErrNoConnectionForDomain is returned by OrganizationIDForDomain when no
+// Connection is associated with the given domain.
+var ErrNoConnectionForDomain = errors.New("no connection found for domain")
+
+// OrganizationIDForDomain returns the ID of the Organization whose Connection
+// is associated with the given domain. It is intended to help migrate from the
+// deprecated `Domain` selector to the `Organization` selector when generating
+// authorization URLs.
+func (c *Client) OrganizationIDForDomain(ctx context.Context, domain string) (string, error) {
+	if domain == "" {
+		return "", errors.New("incomplete arguments: missing domain")
+	}
+
+	connections, err := c.ListConnections(ctx, ListConnectionsOpts{
+		Domain: domain,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, connection := range connections.Data {
+		if connection.OrganizationID != "" {
+			return connection.OrganizationID, nil
+		}
+	}
+
+	return "", ErrNoConnectionForDomain
 }
@@ -292,6 +292,38 @@ func TestClientGetProfile(t *testing.T) {
 	}
 }
 
+func TestClientGetProfileDecodesConnectionAndOrganization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"object": "profile",
+			"id": "prof_01DMC79VCBZ0NY2099737PSVF1",
+			"connection_id": "conn_01E4ZCR3C56J083X43JQXF3JK5",
+			"connection_type": "OktaSAML",
+			"organization_id": "org_01EHWNCE74X7JSDV0X3SZ3KJNY",
+			"email": "todd@foo-corp.com",
+			"first_name": "Todd",
+			"last_name": "Rundgren",
+			"idp_id": "00u1a0ufowBJlzPlk357",
+			"raw_attributes": {}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		APIKey:     "test",
+		ClientID:   "client_123",
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+	}
+
+	profile, err := client.GetProfile(context.Background(), GetProfileOpts{AccessToken: "access_token"})
+	require.NoError(t, err)
+	require.Equal(t, "conn_01E4ZCR3C56J083X43JQXF3JK5", profile.ConnectionID)
+	require.Equal(t, OktaSAML, profile.ConnectionType)
+	require.Equal(t, "org_01EHWNCE74X7JSDV0X3SZ3KJNY", profile.OrganizationID)
+}
+
 func profileTestHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/sso/profile" {
 		fmt.Println("path:", r.URL.Path)
@@ -331,6 +363,48 @@ func profileTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+func TestConnectionTypeUnmarshal(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected ConnectionType
+	}{
+		{raw: `"GenericSAML"`, expected: GenericSAML},
+		{raw: `"OktaSAML"`, expected: OktaSAML},
+		{raw: `"GoogleOAuth"`, expected: GoogleOAuth},
+		{raw: `"MicrosoftOAuth"`, expected: MicrosoftOAuth},
+		{raw: `"SomeFutureSAML"`, expected: ConnectionType("SomeFutureSAML")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			var connection Connection
+			err := json.Unmarshal([]byte(`{"id":"conn_id","connection_type":`+test.raw+`}`), &connection)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, connection.ConnectionType)
+		})
+	}
+}
+
+func TestConnectionDomains(t *testing.T) {
+	var connection Connection
+	err := json.Unmarshal([]byte(`{
+		"object": "connection",
+		"id": "conn_123",
+		"domains": [
+			{"object": "connection_domain", "id": "conn_domain_1", "domain": "foo-corp.com"},
+			{"object": "connection_domain", "id": "conn_domain_2", "domain": "foo-corp.io"}
+		]
+	}`), &connection)
+	require.NoError(t, err)
+
+	require.Equal(t, []ConnectionDomain{
+		{ID: "conn_domain_1", Domain: "foo-corp.com"},
+		{ID: "conn_domain_2", Domain: "foo-corp.io"},
+	}, connection.Domains)
+	require.Equal(t, []string{"foo-corp.com", "foo-corp.io"}, connection.DomainStrings())
+	require.Empty(t, Connection{}.DomainStrings())
+}
+
 func TestGetConnection(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -497,3 +571,90 @@ func listConnectionsTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(body)
 }
+
+func TestOrganizationIDForDomain(t *testing.T) {
+	tests := []struct {
+		scenario string
+		client   *Client
+		domain   string
+		expected string
+		err      error
+	}{
+		{
+			scenario: "Request without API Key returns an error",
+			client:   &Client{},
+			domain:   "foo-corp.com",
+		},
+		{
+			scenario: "Request returns the Organization ID for a domain",
+			client: &Client{
+				APIKey: "test",
+			},
+			domain:   "foo-corp.com",
+			expected: "org_123",
+		},
+		{
+			scenario: "Request for an unknown domain returns an error",
+			client: &Client{
+				APIKey: "test",
+			},
+			domain: "unknown.com",
+			err:    ErrNoConnectionForDomain,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(organizationIDForDomainTestHandler))
+			defer server.Close()
+
+			client := test.client
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			organizationID, err := client.OrganizationIDForDomain(context.Background(), test.domain)
+			if test.expected == "" {
+				require.Error(t, err)
+				if test.err != nil {
+					require.Equal(t, test.err, err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, organizationID)
+		})
+	}
+}
+
+func organizationIDForDomainTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	var data []Connection
+	if r.URL.Query().Get("domain") == "foo-corp.com" {
+		data = []Connection{
+			{
+				ID:             "conn_id",
+				ConnectionType: OktaSAML,
+				State:          Active,
+				Name:           "Foo Corp",
+				OrganizationID: "org_123",
+				Domains: []ConnectionDomain{
+					{ID: "conn_domain_id", Domain: "foo-corp.com"},
+				},
+			},
+		}
+	}
+
+	body, err := json.Marshal(ListConnectionsResponse{Data: data})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
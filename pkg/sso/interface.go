@@ -0,0 +1,20 @@
+package sso
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// SSO is the interface implemented by Client. Code calling the SSO API can
+// depend on it instead of Client, so that a mock can be injected in tests.
+type SSO interface {
+	GetLoginHandler(opts GetAuthorizationURLOpts) http.Handler
+	GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, error)
+	GetProfileAndToken(ctx context.Context, opts GetProfileAndTokenOpts) (ProfileAndToken, error)
+	GetProfile(ctx context.Context, opts GetProfileOpts) (Profile, error)
+	GetConnection(ctx context.Context, opts GetConnectionOpts) (Connection, error)
+	ListConnections(ctx context.Context, opts ListConnectionsOpts) (ListConnectionsResponse, error)
+	DeleteConnection(ctx context.Context, opts DeleteConnectionOpts) error
+	OrganizationIDForDomain(ctx context.Context, domain string) (string, error)
+}
@@ -0,0 +1,3 @@
+package sso
+
+var _ SSO = (*Client)(nil)
@@ -68,3 +68,9 @@ func DeleteConnection(
 ) error {
 	return DefaultClient.DeleteConnection(ctx, opts)
 }
+
+// OrganizationIDForDomain returns the ID of the Organization whose Connection
+// is associated with the given domain.
+func OrganizationIDForDomain(ctx context.Context, domain string) (string, error) {
+	return DefaultClient.OrganizationIDForDomain(ctx, domain)
+}
@@ -0,0 +1,97 @@
+package usermanagement
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BulkResult summarizes the outcome of a bulk operation.
+type BulkResult struct {
+	// The number of items that were processed successfully.
+	Succeeded int
+
+	// The number of items that failed.
+	Failed int
+
+	// The reason of each failure, in the order of the items.
+	Errors []error
+}
+
+// bulk calls fn with the index of each of the n items of a bulk operation,
+// running at most getUsersConcurrency calls at a time, and summarizes their
+// outcome. The errors are prefixed with the description of their item. Once
+// ctx is done, no more calls are started and ctx.Err() is reported for the
+// remaining items.
+func bulk(ctx context.Context, n int, describe func(i int) string, fn func(i int) error) BulkResult {
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, getUsersConcurrency)
+
+	for i := 0; i < n; i++ {
+		if !acquire(ctx, sem) {
+			for j := i; j < n; j++ {
+				errs[j] = ctx.Err()
+			}
+			break
+		}
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	var result BulkResult
+	for i, err := range errs {
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", describe(i), err))
+			continue
+		}
+		result.Succeeded++
+	}
+	return result
+}
+
+// CreateOrganizationMemberships creates the given Organization Memberships
+// concurrently. The reason of each membership that could not be created is
+// reported in the returned BulkResult.
+func (c *Client) CreateOrganizationMemberships(ctx context.Context, opts []CreateOrganizationMembershipOpts) BulkResult {
+	return bulk(ctx, len(opts), func(i int) string {
+		return fmt.Sprintf("membership of user %s in organization %s", opts[i].UserID, opts[i].OrganizationID)
+	}, func(i int) error {
+		_, err := c.CreateOrganizationMembership(ctx, opts[i])
+		return err
+	})
+}
+
+// SendInvitations sends the given Invitations concurrently. The reason of each
+// Invitation that could not be sent is reported in the returned BulkResult.
+func (c *Client) SendInvitations(ctx context.Context, opts []SendInvitationOpts) BulkResult {
+	return bulk(ctx, len(opts), func(i int) string {
+		return "invitation of " + opts[i].Email
+	}, func(i int) error {
+		_, err := c.SendInvitation(ctx, opts[i])
+		return err
+	})
+}
+
+// RevokeInvitations revokes the Invitations with the given IDs concurrently.
+// The reason of each Invitation that could not be revoked is reported in the
+// returned BulkResult.
+func (c *Client) RevokeInvitations(ctx context.Context, ids []string) BulkResult {
+	return bulk(ctx, len(ids), func(i int) string {
+		return "invitation " + ids[i]
+	}, func(i int) error {
+		_, err := c.RevokeInvitation(ctx, RevokeInvitationOpts{Invitation: ids[i]})
+		return err
+	})
+}
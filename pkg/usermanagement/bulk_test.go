@@ -0,0 +1,158 @@
+package usermanagement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bulkTestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	var body []byte
+	var err error
+
+	switch {
+	case r.URL.Path == "/user_management/organization_memberships":
+		var opts CreateOrganizationMembershipOpts
+		if err = json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.UserID == "user_missing" {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		body, err = json.Marshal(OrganizationMembership{
+			ID:             "om_" + opts.UserID,
+			UserID:         opts.UserID,
+			OrganizationID: opts.OrganizationID,
+		})
+
+	case r.URL.Path == "/user_management/invitations":
+		var opts SendInvitationOpts
+		if err = json.NewDecoder(r.Body).Decode(&opts); err != nil || !strings.Contains(opts.Email, "@") {
+			http.Error(w, "invalid email", http.StatusUnprocessableEntity)
+			return
+		}
+		body, err = json.Marshal(Invitation{ID: "invitation_123", Email: opts.Email})
+
+	case strings.HasSuffix(r.URL.Path, "/revoke"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/user_management/invitations/"), "/revoke")
+		if id == "invitation_missing" {
+			http.Error(w, "invitation not found", http.StatusNotFound)
+			return
+		}
+		body, err = json.Marshal(Invitation{ID: id, State: "revoked"})
+
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestBulkOperations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(bulkTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	ctx := context.Background()
+
+	t.Run("CreateOrganizationMemberships", func(t *testing.T) {
+		result := client.CreateOrganizationMemberships(ctx, []CreateOrganizationMembershipOpts{
+			{UserID: "user_1", OrganizationID: "org_123"},
+			{UserID: "user_missing", OrganizationID: "org_123"},
+			{UserID: "user_2", OrganizationID: "org_123"},
+		})
+		require.Equal(t, 2, result.Succeeded)
+		require.Equal(t, 1, result.Failed)
+		require.Len(t, result.Errors, 1)
+		require.Contains(t, result.Errors[0].Error(), "user_missing")
+	})
+
+	t.Run("SendInvitations", func(t *testing.T) {
+		result := client.SendInvitations(ctx, []SendInvitationOpts{
+			{Email: "invalid"},
+			{Email: "marcelina@foo-corp.com"},
+			{Email: "also-invalid"},
+		})
+		require.Equal(t, 1, result.Succeeded)
+		require.Equal(t, 2, result.Failed)
+		require.Len(t, result.Errors, 2)
+		require.Contains(t, result.Errors[0].Error(), "invalid")
+		require.Contains(t, result.Errors[1].Error(), "also-invalid")
+	})
+
+	t.Run("RevokeInvitations", func(t *testing.T) {
+		result := client.RevokeInvitations(ctx, []string{"invitation_1", "invitation_2", "invitation_missing"})
+		require.Equal(t, 2, result.Succeeded)
+		require.Equal(t, 1, result.Failed)
+		require.Len(t, result.Errors, 1)
+		require.Contains(t, result.Errors[0].Error(), "invitation_missing")
+	})
+
+	t.Run("Empty input", func(t *testing.T) {
+		require.Equal(t, BulkResult{}, client.RevokeInvitations(ctx, nil))
+	})
+}
+
+func TestBulkOperationsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		if requests == getUsersConcurrency {
+			cancel()
+		}
+		mu.Unlock()
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	ids := make([]string, 3*getUsersConcurrency)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("invitation_%d", i)
+	}
+
+	result := client.RevokeInvitations(ctx, ids)
+	require.Equal(t, 0, result.Succeeded)
+	require.Equal(t, len(ids), result.Failed)
+	for i, err := range result.Errors {
+		require.True(t, errors.Is(err, context.Canceled), err.Error())
+		require.Contains(t, err.Error(), ids[i])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, getUsersConcurrency, requests)
+}
@@ -0,0 +1,127 @@
+package usermanagement
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// DefaultMaxCachedUsers is the number of Users a CachingClient keeps when its
+// MaxUsers is not set.
+const DefaultMaxCachedUsers = 10000
+
+// CachingClient is a Client that caches the Users it gets. Cached Users are
+// revalidated with conditional requests, using the ETag returned by the API,
+// so that unchanged Users are not downloaded again.
+//
+// The other methods are the ones of the embedded Client.
+type CachingClient struct {
+	*Client
+
+	// The maximum number of Users kept in the cache. Once it is reached, the
+	// least recently read Users are evicted. Defaults to
+	// DefaultMaxCachedUsers.
+	//
+	// OPTIONAL.
+	MaxUsers int
+
+	mu    sync.Mutex
+	users map[string]*list.Element
+	lru   *list.List
+}
+
+// cachedUser is a User cached by a CachingClient.
+type cachedUser struct {
+	user User
+	etag string
+}
+
+// NewCachingClient returns a CachingClient wrapping the given client.
+func NewCachingClient(client *Client) *CachingClient {
+	return &CachingClient{
+		Client: client,
+		users:  make(map[string]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// GetUser returns details of an existing user, from the cache when the API
+// reports that the User did not change since it was cached.
+func (c *CachingClient) GetUser(ctx context.Context, opts GetUserOpts) (User, error) {
+	cached, ok := c.cached(opts.User)
+
+	user, etag, err := c.Client.getUser(ctx, opts, cached.etag)
+	if ok && err == errNotModified {
+		return cached.user, nil
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	if etag != "" {
+		c.cache(opts.User, cachedUser{user: user, etag: etag})
+	} else {
+		c.evict(opts.User)
+	}
+	return user, nil
+}
+
+// cached returns the cached User with the given ID, marking it as the most
+// recently read one.
+func (c *CachingClient) cached(id string) (cachedUser, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.users[id]
+	if !ok {
+		return cachedUser{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*lruEntry).user, true
+}
+
+// cache caches the User with the given ID, evicting the least recently read
+// Users when the cache is full.
+func (c *CachingClient) cache(id string, user cachedUser) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.users == nil {
+		c.users = make(map[string]*list.Element)
+		c.lru = list.New()
+	}
+
+	if elem, ok := c.users[id]; ok {
+		elem.Value.(*lruEntry).user = user
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.users[id] = c.lru.PushFront(&lruEntry{id: id, user: user})
+
+	maxUsers := c.MaxUsers
+	if maxUsers <= 0 {
+		maxUsers = DefaultMaxCachedUsers
+	}
+	for c.lru.Len() > maxUsers {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.users, oldest.Value.(*lruEntry).id)
+	}
+}
+
+// evict removes the User with the given ID from the cache.
+func (c *CachingClient) evict(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.users[id]; ok {
+		c.lru.Remove(elem)
+		delete(c.users, id)
+	}
+}
+
+// lruEntry is an element of the eviction list of a CachingClient.
+type lruEntry struct {
+	id   string
+	user cachedUser
+}
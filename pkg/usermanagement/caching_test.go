@@ -0,0 +1,93 @@
+package usermanagement
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachingClientGetUser(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		body, err := json.Marshal(User{ID: "user_123", Email: "marcelina@foo-corp.com"})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	cachingClient := NewCachingClient(client)
+
+	expected := User{ID: "user_123", Email: "marcelina@foo-corp.com"}
+
+	user, err := cachingClient.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Equal(t, expected, user)
+
+	user, err = cachingClient.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Equal(t, expected, user)
+
+	require.Equal(t, []string{"", `"v1"`}, ifNoneMatch)
+
+	_, err = client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Equal(t, "", ifNoneMatch[2])
+}
+
+func TestCachingClientEvictsLeastRecentlyReadUsers(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/user_management/users/")
+		body, err := json.Marshal(User{ID: id})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", `"`+id+`"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	cachingClient := NewCachingClient(client)
+	cachingClient.MaxUsers = 2
+
+	for _, id := range []string{"user_1", "user_2", "user_1", "user_3", "user_1", "user_2"} {
+		user, err := cachingClient.GetUser(context.Background(), GetUserOpts{User: id})
+		require.NoError(t, err)
+		require.Equal(t, id, user.ID)
+	}
+
+	// user_2 is evicted when user_3 is cached, as user_1 was read more
+	// recently.
+	require.Equal(t, []string{"", "", `"user_1"`, "", `"user_1"`, ""}, ifNoneMatch)
+	require.Len(t, cachingClient.users, 2)
+}
@@ -3,11 +3,16 @@ package usermanagement
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -30,6 +35,10 @@ const (
 )
 
 // InvitationState represents the state of an Invitation.
+//
+// States introduced by the API after this version of the SDK are decoded as
+// is, so compare against the constants below rather than assuming the list is
+// exhaustive.
 type InvitationState string
 
 // Constants that enumerate the state of an Invitation.
@@ -51,6 +60,18 @@ type Invitation struct {
 	ExpiresAt      string          `json:"expires_at"`
 	CreatedAt      string          `json:"created_at"`
 	UpdatedAt      string          `json:"updated_at"`
+
+	// The metadata the Invitation was sent with.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ExpiresAtTime returns the time at which the Invitation expires, in UTC.
+func (i Invitation) ExpiresAtTime() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, i.ExpiresAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
 }
 
 // Organization contains data about a particular Organization.
@@ -62,6 +83,17 @@ type Organization struct {
 	Name string `json:"name"`
 }
 
+// OrganizationMembershipStatus represents the status of an
+// OrganizationMembership.
+type OrganizationMembershipStatus string
+
+// Constants that enumerate the status of an OrganizationMembership.
+const (
+	OrganizationMembershipActive   OrganizationMembershipStatus = "active"
+	OrganizationMembershipInactive OrganizationMembershipStatus = "inactive"
+	OrganizationMembershipPending  OrganizationMembershipStatus = "pending"
+)
+
 // OrganizationMembership contains data about a particular OrganizationMembership.
 type OrganizationMembership struct {
 	// The Organization Membership's unique identifier.
@@ -73,6 +105,12 @@ type OrganizationMembership struct {
 	// The ID of the Organization.
 	OrganizationID string `json:"organization_id"`
 
+	// The User's role in the Organization.
+	Role RoleResponse `json:"role"`
+
+	// The status of the OrganizationMembership.
+	Status OrganizationMembershipStatus `json:"status,omitempty"`
+
 	// CreatedAt is the timestamp of when the OrganizationMembership was created.
 	CreatedAt string `json:"created_at"`
 
@@ -80,6 +118,63 @@ type OrganizationMembership struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+// RoleResponse contains data about the role of an OrganizationMembership.
+type RoleResponse struct {
+	// The slug of the role, eg. "member" or "admin".
+	Slug string `json:"slug"`
+
+	// The permissions granted by the role. Only set when the membership is
+	// read with the ExpandRolePermissions expansion.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Role contains data about a role that can be assigned to the members of an
+// Organization.
+type Role struct {
+	// The Role's unique identifier.
+	ID string `json:"id"`
+
+	// The Role's name.
+	Name string `json:"name"`
+
+	// The slug of the Role, eg. "member" or "admin".
+	Slug string `json:"slug"`
+
+	// The Role's description.
+	Description string `json:"description"`
+
+	// The permissions granted by the Role.
+	Permissions []string `json:"permissions"`
+
+	// Whether the Role is defined for the environment or for the Organization.
+	Type string `json:"type"`
+
+	// CreatedAt is the timestamp of when the Role was created.
+	CreatedAt string `json:"created_at"`
+
+	// UpdatedAt is the timestamp of when the Role was updated.
+	UpdatedAt string `json:"updated_at"`
+}
+
+// MembershipWithRole is an Organization Membership along with the Role of the
+// member.
+type MembershipWithRole struct {
+	Membership OrganizationMembership
+
+	// The Role of the member in the Organization. Only its Slug and
+	// Permissions are set when the Role could not be found.
+	Role Role
+}
+
+// OrganizationSummary contains the identifying data of an Organization.
+type OrganizationSummary struct {
+	// The Organization's unique identifier.
+	ID string `json:"id"`
+
+	// The Organization's name.
+	Name string `json:"name"`
+}
+
 // User contains data about a particular User.
 type User struct {
 
@@ -106,6 +201,10 @@ type User struct {
 
 	// A URL reference to an image representing the User.
 	ProfilePictureURL string `json:"profile_picture_url"`
+
+	// The timestamp of when the User last signed in. Empty when the User
+	// never signed in.
+	LastSignInAt string `json:"last_sign_in_at,omitempty"`
 }
 
 // GetUserOpts contains the options to pass in order to get a user profile.
@@ -123,6 +222,15 @@ type ListUsersResponse struct {
 	ListMetadata common.ListMetadata `json:"list_metadata"`
 }
 
+// ByID returns the Users of the response indexed by their ID.
+func (r ListUsersResponse) ByID() map[string]User {
+	users := make(map[string]User, len(r.Data))
+	for _, user := range r.Data {
+		users[user.ID] = user
+	}
+	return users
+}
+
 type ListUsersOpts struct {
 	// Filter Users by their email.
 	Email string `url:"email,omitempty"`
@@ -159,13 +267,32 @@ const (
 	Bcrypt PasswordHashType = "bcrypt"
 )
 
+// UpdateUserOpts contains the options to update a User.
+//
+// UpdateUser has partial update semantics: fields left to their zero value are
+// not sent to WorkOS, so the corresponding User attributes are left unchanged.
 type UpdateUserOpts struct {
-	User             string
-	FirstName        string           `json:"first_name,omitempty"`
-	LastName         string           `json:"last_name,omitempty"`
-	EmailVerified    bool             `json:"email_verified,omitempty"`
-	Password         string           `json:"password,omitempty"`
-	PasswordHash     string           `json:"password_hash,omitempty"`
+	// The unique ID of the User to update.
+	User string `json:"-"`
+
+	// The User's new first name. Omitted when empty.
+	FirstName string `json:"first_name,omitempty"`
+
+	// The User's new last name. Omitted when empty.
+	LastName string `json:"last_name,omitempty"`
+
+	// Whether the User's email is verified. Only sent when set, so that
+	// updating other attributes never changes the verification state.
+	EmailVerified *bool `json:"email_verified,omitempty"`
+
+	// The User's new password. Omitted when empty.
+	Password string `json:"password,omitempty"`
+
+	// A hash of the User's new password, to use instead of Password when
+	// migrating users. Omitted when empty.
+	PasswordHash string `json:"password_hash,omitempty"`
+
+	// The algorithm used to compute PasswordHash.
 	PasswordHashType PasswordHashType `json:"password_hash_type,omitempty"`
 }
 
@@ -186,6 +313,42 @@ type AuthenticateWithCodeOpts struct {
 	Code      string `json:"code"`
 	IPAddress string `json:"ip_address,omitempty"`
 	UserAgent string `json:"user_agent,omitempty"`
+
+	// The PKCE code verifier the code challenge of the authorization URL was
+	// derived from. It must be between 43 and 128 characters among letters,
+	// digits, "-", ".", "_" and "~".
+	//
+	// OPTIONAL.
+	CodeVerifier string `json:"code_verifier,omitempty"`
+}
+
+// Bounds of the length of AuthenticateWithCodeOpts.CodeVerifier.
+const (
+	MinCodeVerifierLength = 43
+	MaxCodeVerifierLength = 128
+)
+
+// validateCodeVerifier checks that a PKCE code verifier is made of the
+// characters and has the length allowed by RFC 7636.
+func validateCodeVerifier(verifier string) error {
+	if len(verifier) < MinCodeVerifierLength || len(verifier) > MaxCodeVerifierLength {
+		return fmt.Errorf(
+			"invalid arguments: CodeVerifier must be between %d and %d characters, got %d",
+			MinCodeVerifierLength,
+			MaxCodeVerifierLength,
+			len(verifier),
+		)
+	}
+
+	for _, r := range verifier {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case r == '-', r == '.', r == '_', r == '~':
+		default:
+			return fmt.Errorf("invalid arguments: CodeVerifier contains invalid character %q", r)
+		}
+	}
+	return nil
 }
 
 type AuthenticateWithMagicAuthOpts struct {
@@ -226,6 +389,16 @@ type AuthenticateWithOrganizationSelectionOpts struct {
 	UserAgent                  string `json:"user_agent,omitempty"`
 }
 
+type AuthenticateWithRefreshTokenOpts struct {
+	ClientID     string `json:"client_id"`
+	RefreshToken string `json:"refresh_token"`
+
+	// The Organization to scope the new access token to. OPTIONAL.
+	OrganizationID string `json:"organization_id,omitempty"`
+	IPAddress      string `json:"ip_address,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+}
+
 type AuthenticateResponse struct {
 	User User `json:"user"`
 
@@ -235,6 +408,111 @@ type AuthenticateResponse struct {
 	// If the user is a member of only one organization, this is that organization.
 	// If the user is not a member of any organizations, this is null.
 	OrganizationID string `json:"organization_id"`
+
+	// The access token for the authenticated session. It is a JWT whose claims
+	// can be read with AccessTokenClaims.
+	AccessToken string `json:"access_token,omitempty"`
+
+	// The refresh token that can be exchanged for a new access token.
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// The number of seconds the access token is valid for.
+	ExpiresIn int `json:"expires_in,omitempty"`
+
+	// The time at which the access token expires, computed from ExpiresIn
+	// with the client's clock when the response is decoded. Zero when the
+	// response has no ExpiresIn.
+	AccessTokenExpiresAt time.Time `json:"-"`
+
+	// The WorkOS Dashboard user who is impersonating the User, when the
+	// session was started through impersonation. Nil otherwise.
+	Impersonator *Impersonator `json:"impersonator,omitempty"`
+}
+
+// Impersonator contains the details of a WorkOS Dashboard user impersonating a
+// User.
+type Impersonator struct {
+	// The email address of the Dashboard user doing the impersonation.
+	Email string `json:"email"`
+
+	// The justification the Dashboard user gave for the impersonation. Empty
+	// when the environment does not require one.
+	Reason string `json:"reason,omitempty"`
+}
+
+// IsImpersonated reports whether the session was started by a WorkOS
+// Dashboard user impersonating the User.
+func (r AuthenticateResponse) IsImpersonated() bool {
+	return r.Impersonator != nil
+}
+
+// decodeAuthenticateResponse decodes an AuthenticateResponse and computes its
+// AccessTokenExpiresAt.
+func (c *Client) decodeAuthenticateResponse(r io.Reader) (AuthenticateResponse, error) {
+	var body AuthenticateResponse
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return body, err
+	}
+
+	if body.ExpiresIn > 0 {
+		body.AccessTokenExpiresAt = c.now().Add(time.Duration(body.ExpiresIn) * time.Second).UTC()
+	}
+	return body, nil
+}
+
+// AccessTokenClaims contains the claims of an access token issued by WorkOS.
+type AccessTokenClaims struct {
+	// The ID of the authenticated User.
+	Subject string `json:"sub"`
+
+	// The ID of the session the access token belongs to.
+	SessionID string `json:"sid"`
+
+	// The ID of the Organization the session is scoped to. Empty when the
+	// session is not scoped to an Organization.
+	OrganizationID string `json:"org_id"`
+
+	// The slug of the User's role in the Organization.
+	Role string `json:"role"`
+
+	// The permissions granted by the User's role in the Organization.
+	Permissions []string `json:"permissions"`
+
+	// The time at which the access token expires, in seconds since epoch.
+	ExpiresAt int64 `json:"exp"`
+
+	// The time at which the access token was issued, in seconds since epoch.
+	IssuedAt int64 `json:"iat"`
+}
+
+// AccessTokenClaims returns the claims of the response's access token, which
+// include the User's role and permissions in the selected Organization.
+//
+// The token signature is not verified: the claims are only meant to be read
+// from a response that was received directly from WorkOS.
+func (r AuthenticateResponse) AccessTokenClaims() (AccessTokenClaims, error) {
+	if r.AccessToken == "" {
+		return AccessTokenClaims{}, errors.New("authenticate response has no access token")
+	}
+	return decodeAccessTokenClaims(r.AccessToken)
+}
+
+func decodeAccessTokenClaims(token string) (AccessTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return AccessTokenClaims{}, errors.New("malformed access token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return AccessTokenClaims{}, fmt.Errorf("malformed access token: %w", err)
+	}
+
+	var claims AccessTokenClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return AccessTokenClaims{}, fmt.Errorf("malformed access token: %w", err)
+	}
+	return claims, nil
 }
 
 type SendVerificationEmailOpts struct {
@@ -261,7 +539,7 @@ type ResetPasswordOpts struct {
 	// The verification token emailed to the user.
 	Token string `json:"token"`
 
-	// The new password to be set for the user.
+	// The new password to be set for the user. It is required.
 	NewPassword string `json:"new_password"`
 }
 
@@ -296,9 +574,37 @@ type ListAuthFactorsResponse struct {
 	ListMetadata common.ListMetadata `json:"list_metadata"`
 }
 
+// Expansion represents related data that can be expanded when reading an
+// Organization Membership.
+type Expansion string
+
+// Constants that enumerate the available expansions.
+const (
+	// Attaches the permissions of the role to the membership's Role. The Role
+	// is left as is when it is not listed by ListOrganizationRoles.
+	ExpandRolePermissions Expansion = "role.permissions"
+)
+
 type GetOrganizationMembershipOpts struct {
 	// Organization Membership unique identifier
 	OrganizationMembership string
+
+	// Related data to expand, at the cost of additional requests.
+	//
+	// OPTIONAL.
+	Expand []Expansion
+}
+
+type ListOrganizationRolesOpts struct {
+	// The ID of the Organization.
+	OrganizationID string
+}
+
+// ListOrganizationRolesResponse contains the response from the
+// ListOrganizationRoles call.
+type ListOrganizationRolesResponse struct {
+	// List of Roles
+	Data []Role `json:"data"`
 }
 
 type ListOrganizationMembershipsOpts struct {
@@ -308,10 +614,22 @@ type ListOrganizationMembershipsOpts struct {
 	// Filter memberships by User ID.
 	UserID string `url:"user_id,omitempty"`
 
+	// Filter memberships by status. Memberships of any status are listed when
+	// empty.
+	Statuses []OrganizationMembershipStatus `url:"statuses,comma,omitempty"`
+
+	// Filter memberships by the slug of their role, eg. "admin". The API does
+	// not filter memberships by role, so this filter is applied by the client
+	// to each page after it is fetched: a page can hold fewer memberships than
+	// Limit, or none at all, while its ListMetadata still points to the next
+	// page.
+	RoleSlug string `url:"-"`
+
 	// Maximum number of records to return.
 	Limit int `url:"limit"`
 
-	// The order in which to paginate records.
+	// The order in which to paginate records, by creation date. Use Asc to
+	// list the oldest members first. Defaults to Desc, newest members first.
 	Order Order `url:"order,omitempty"`
 
 	// Pagination cursor to receive records before a provided
@@ -335,6 +653,18 @@ type CreateOrganizationMembershipOpts struct {
 
 	// The ID of the Organization in which to add the User as a member.
 	OrganizationID string `json:"organization_id"`
+
+	// The slug of the role to grant to the User. Defaults to the
+	// Organization's default role when empty.
+	RoleSlug string `json:"role_slug,omitempty"`
+}
+
+type UpdateOrganizationMembershipOpts struct {
+	// The ID of the Organization Membership to update.
+	OrganizationMembership string `json:"-"`
+
+	// The slug of the role to grant to the User.
+	RoleSlug string `json:"role_slug"`
 }
 
 type DeleteOrganizationMembershipOpts struct {
@@ -346,6 +676,11 @@ type GetInvitationOpts struct {
 	Invitation string
 }
 
+type FindInvitationByTokenOpts struct {
+	// The token of the Invitation, as sent in the invitation email.
+	InvitationToken string
+}
+
 // ListInvitations contains the response from the ListInvitations call.
 type ListInvitationsResponse struct {
 	// List of Invitations
@@ -356,9 +691,9 @@ type ListInvitationsResponse struct {
 }
 
 type ListInvitationsOpts struct {
-	OrganizationID string `json:"organization_id,omitempty"`
+	OrganizationID string `url:"organization_id,omitempty"`
 
-	Email string `json:"email,omitempty"`
+	Email string `url:"email,omitempty"`
 
 	// Maximum number of records to return.
 	Limit int `url:"limit"`
@@ -373,72 +708,220 @@ type ListInvitationsOpts struct {
 	After string `url:"after,omitempty"`
 }
 
+// Bounds of SendInvitationOpts.ExpiresInDays accepted by WorkOS.
+const (
+	MinInvitationExpiresInDays = 1
+	MaxInvitationExpiresInDays = 30
+)
+
 type SendInvitationOpts struct {
 	Email          string `json:"email"`
 	OrganizationID string `json:"organization_id,omitempty"`
-	ExpiresInDays  int    `json:"expires_in_days,omitempty"`
-	InviterUserID  string `json:"inviter_user_id,omitempty"`
+
+	// The number of days the Invitation is valid for, between
+	// MinInvitationExpiresInDays and MaxInvitationExpiresInDays. Defaults to
+	// the WorkOS default when zero.
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+	InviterUserID string `json:"inviter_user_id,omitempty"`
+
+	// Context attached to the Invitation, eg. a referral source, returned with
+	// the Invitation so that it can be read when the Invitation is accepted.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type RevokeInvitationOpts struct {
 	Invitation string
 }
 
+// ResendInvitationOpts contains the options to resend an Invitation.
+type ResendInvitationOpts struct {
+	// The ID of the Invitation to resend.
+	Invitation string
+}
+
+// AcceptInvitationOpts contains the options to accept an Invitation.
+type AcceptInvitationOpts struct {
+	// The ID of the Invitation to accept.
+	Invitation string
+}
+
 func NewClient(apiKey string) *Client {
 	return &Client{
 		APIKey:     apiKey,
-		Endpoint:   "https://api.workos.com",
 		HTTPClient: &http.Client{Timeout: time.Second * 10},
 		JSONEncode: json.Marshal,
 	}
 }
 
+// endpoint returns the endpoint of the WorkOS API. When Endpoint is not set,
+// the default endpoint is resolved on every call, so that WORKOS_API_ENDPOINT
+// applies even when it is set after the client was created.
+func (c *Client) endpoint() string {
+	if c.Endpoint != "" {
+		return c.Endpoint
+	}
+	return workos.DefaultEndpoint()
+}
+
+// now returns the current time according to the client's clock.
+func (c *Client) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// do sends the given request with the client's HTTPClient and hooks.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return workos.Do(c.HTTPClient, req, workos.Hooks{
+		APIVersion:        c.APIVersion,
+		DefaultHeaders:    c.DefaultHeaders,
+		OnRequest:         c.OnRequest,
+		OnRequestComplete: c.OnRequestComplete,
+		MaxResponseBytes:  c.MaxResponseBytes,
+	})
+}
+
+// tryGetHTTPError returns the error of a failed response, passed through the
+// ErrorHandler when one is configured.
+func (c *Client) tryGetHTTPError(res *http.Response) error {
+	return workos.TryGetHTTPError(res, c.ErrorHandler)
+}
+
 // GetUser returns details of an existing user
 func (c *Client) GetUser(ctx context.Context, opts GetUserOpts) (User, error) {
+	user, _, err := c.getUser(ctx, opts, "")
+	return user, err
+}
+
+// errNotModified is returned by getUser when the User still matches the given
+// ETag.
+var errNotModified = errors.New("not modified")
+
+// getUser gets a User along with its ETag. When etag is not empty, the request
+// is conditional and errNotModified is returned when the User is unchanged.
+func (c *Client) getUser(ctx context.Context, opts GetUserOpts, etag string) (User, string, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
 	)
 	if err != nil {
-		return User{}, err
+		return User{}, "", err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
-		return User{}, err
+		return User{}, "", err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
-		return User{}, err
+	if etag != "" && res.StatusCode == http.StatusNotModified {
+		return User{}, etag, errNotModified
+	}
+	if err = c.tryGetHTTPError(res); err != nil {
+		return User{}, "", err
 	}
 
 	var body User
 	dec := json.NewDecoder(res.Body)
 	err = dec.Decode(&body)
 
-	return body, err
+	return body, res.Header.Get("ETag"), err
+}
+
+// getUsersConcurrency is the maximum number of concurrent requests made by
+// GetUsers and the bulk methods.
+const getUsersConcurrency = 5
+
+// GetUsers returns the details of the Users with the given IDs, fetched
+// concurrently. Users that could not be fetched are missing from the returned
+// map, and the reason is reported in the returned errors. Once ctx is done, no
+// more Users are fetched and ctx.Err() is reported for the remaining ones.
+func (c *Client) GetUsers(ctx context.Context, ids []string) (map[string]User, []error) {
+	users := make(map[string]User, len(ids))
+	var errs []error
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, getUsersConcurrency)
+
+	for i, id := range ids {
+		if !acquire(ctx, sem) {
+			mu.Lock()
+			for _, id := range ids[i:] {
+				errs = append(errs, fmt.Errorf("user %s: %w", id, ctx.Err()))
+			}
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			user, err := c.GetUser(ctx, GetUserOpts{User: id})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", id, err))
+				return
+			}
+			users[id] = user
+		}(id)
+	}
+
+	wg.Wait()
+	return users, errs
+}
+
+// acquire takes a slot of the given semaphore, and reports false without
+// taking one when ctx is done first.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case sem <- struct{}{}:
+		// Both cases may be ready at once, select picks one at random.
+		if ctx.Err() != nil {
+			<-sem
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 // ListUsers get a list of all of your existing users matching the criteria specified.
 func (c *Client) ListUsers(ctx context.Context, opts ListUsersOpts) (ListUsersResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -446,7 +929,6 @@ func (c *Client) ListUsers(ctx context.Context, opts ListUsersOpts) (ListUsersRe
 	if err != nil {
 		return ListUsersResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
@@ -462,13 +944,13 @@ func (c *Client) ListUsers(ctx context.Context, opts ListUsersOpts) (ListUsersRe
 
 	req.URL.RawQuery = queryValues.Encode()
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListUsersResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListUsersResponse{}, err
 	}
 
@@ -479,12 +961,81 @@ func (c *Client) ListUsers(ctx context.Context, opts ListUsersOpts) (ListUsersRe
 	return body, err
 }
 
+// ErrUserNotFound is returned by GetUserByEmail when no User has the given
+// email.
+var ErrUserNotFound = errors.New("user not found")
+
+// GetUserByEmail gets the User with the given email. It returns
+// ErrUserNotFound when there is none.
+func (c *Client) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	if email == "" {
+		return User{}, errors.New("incomplete arguments: missing email")
+	}
+
+	res, err := c.ListUsers(ctx, ListUsersOpts{Email: email, Limit: 1})
+	if err != nil {
+		return User{}, err
+	}
+	if len(res.Data) == 0 {
+		return User{}, ErrUserNotFound
+	}
+	return res.Data[0], nil
+}
+
+// IsEmailAvailable reports whether no User has the given email yet, eg. to
+// validate a signup form before calling CreateUser.
+func (c *Client) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
+	_, err := c.GetUserByEmail(ctx, email)
+	if errors.Is(err, ErrUserNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// IncrementalUserSync gets the Users that were updated after the given time,
+// eg. the time of the last synchronization.
+//
+// The API neither filters nor orders Users by update time, so there is no
+// page after which no updated User can be found: every call lists all the
+// Users of the environment, one request per 100 Users, and filters them on
+// their UpdatedAt timestamp. Only the returned slice is incremental, which
+// makes this method unsuited to frequent syncs of large directories; prefer
+// consuming user events with the events package in that case.
+func (c *Client) IncrementalUserSync(ctx context.Context, since time.Time) ([]User, error) {
+	var users []User
+
+	err := common.Paginate(ctx, func(ctx context.Context, after string) (string, error) {
+		res, err := c.ListUsers(ctx, ListUsersOpts{Limit: 100, After: after})
+		if err != nil {
+			return "", err
+		}
+
+		for _, user := range res.Data {
+			updatedAt, err := time.Parse(time.RFC3339, user.UpdatedAt)
+			if err != nil {
+				return "", fmt.Errorf("user %s: invalid updated_at: %w", user.ID, err)
+			}
+			if updatedAt.After(since) {
+				users = append(users, user)
+			}
+		}
+		return res.ListMetadata.After, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // CreateUser create a new user with email password authentication.
 // Only unmanaged users can be created directly using the User Management API.
 func (c *Client) CreateUser(ctx context.Context, opts CreateUserOpts) (User, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
 	data, err := c.JSONEncode(opts)
@@ -492,7 +1043,8 @@ func (c *Client) CreateUser(ctx context.Context, opts CreateUserOpts) (User, err
 		return User{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -500,18 +1052,17 @@ func (c *Client) CreateUser(ctx context.Context, opts CreateUserOpts) (User, err
 	if err != nil {
 		return User{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return User{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return User{}, err
 	}
 
@@ -526,7 +1077,7 @@ func (c *Client) CreateUser(ctx context.Context, opts CreateUserOpts) (User, err
 func (c *Client) UpdateUser(ctx context.Context, opts UpdateUserOpts) (User, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
 
@@ -535,7 +1086,8 @@ func (c *Client) UpdateUser(ctx context.Context, opts UpdateUserOpts) (User, err
 		return User{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPut,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -543,18 +1095,17 @@ func (c *Client) UpdateUser(ctx context.Context, opts UpdateUserOpts) (User, err
 	if err != nil {
 		return User{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return User{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return User{}, err
 	}
 
@@ -569,11 +1120,12 @@ func (c *Client) UpdateUser(ctx context.Context, opts UpdateUserOpts) (User, err
 func (c *Client) DeleteUser(ctx context.Context, opts DeleteUserOpts) error {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodDelete,
 		endpoint,
 		nil,
@@ -581,18 +1133,61 @@ func (c *Client) DeleteUser(ctx context.Context, opts DeleteUserOpts) error {
 	if err != nil {
 		return err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
+}
+
+// DeleteUserWithMemberships deletes the Organization Memberships of a User,
+// then the User, and returns the memberships that were deleted. It is meant for
+// environments where deleting a User does not cascade to its memberships.
+//
+// It is safe to retry after a failure: memberships and Users that are already
+// deleted are skipped.
+func (c *Client) DeleteUserWithMemberships(ctx context.Context, opts DeleteUserOpts) ([]OrganizationMembership, error) {
+	if opts.User == "" {
+		return nil, errors.New("incomplete arguments: missing User")
+	}
+
+	memberships, err := c.listAllOrganizationMemberships(ctx, ListOrganizationMembershipsOpts{
+		UserID: opts.User,
+	})
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	var deleted []OrganizationMembership
+	for _, membership := range memberships {
+		err := c.DeleteOrganizationMembership(ctx, DeleteOrganizationMembershipOpts{
+			OrganizationMembership: membership.ID,
+		})
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, membership)
+	}
+
+	if err := c.DeleteUser(ctx, opts); err != nil && !isNotFound(err) {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// isNotFound reports whether err is an HTTP 404 error.
+func isNotFound(err error) bool {
+	var httpError workos_errors.HTTPError
+	return errors.As(err, &httpError) && httpError.Code == http.StatusNotFound
 }
 
 // GetAuthorizationURLOpts contains the options to pass in order to generate
@@ -630,8 +1225,19 @@ type GetAuthorizationURLOpts struct {
 	// Domain hint that will be passed as a parameter to the IdP login page.
 	// OPTIONAL.
 	DomainHint string
+
+	// Additional query parameters to add to the authorization URL. They cannot
+	// override the parameters set from the other options, except response_type
+	// which defaults to ResponseTypeCode. Empty values are ignored.
+	//
+	// OPTIONAL.
+	AdditionalParams map[string]string
 }
 
+// ResponseTypeCode is the default response_type of the authorization URLs,
+// requesting an authorization code.
+const ResponseTypeCode = "code"
+
 // GetAuthorizationURL generates an OAuth 2.0 authorization URL.
 // To indicate the connection to use for authentication, use one of the following connection selectors:
 // connection_id, organization_id, or provider.
@@ -641,7 +1247,7 @@ func (c *Client) GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, er
 	query := make(url.Values, 5)
 	query.Set("client_id", opts.ClientID)
 	query.Set("redirect_uri", opts.RedirectURI)
-	query.Set("response_type", "code")
+	query.Set("response_type", ResponseTypeCode)
 
 	if opts.ClientID == "" {
 		return nil, errors.New("incomplete arguments: missing ClientID")
@@ -649,6 +1255,9 @@ func (c *Client) GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, er
 	if opts.RedirectURI == "" {
 		return nil, errors.New("incomplete arguments: missing RedirectURI")
 	}
+	if !c.isAllowedRedirectURI(opts.RedirectURI) {
+		return nil, fmt.Errorf("invalid arguments: RedirectURI %q is not allowed", opts.RedirectURI)
+	}
 	if opts.Provider == "" && opts.ConnectionID == "" && opts.OrganizationID == "" {
 		return nil, errors.New("incomplete arguments: missing ConnectionID, OrganizationID, or Provider")
 	}
@@ -670,8 +1279,14 @@ func (c *Client) GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, er
 	if opts.State != "" {
 		query.Set("state", opts.State)
 	}
+	for key, value := range opts.AdditionalParams {
+		if value == "" || (key != "response_type" && query.Get(key) != "") {
+			continue
+		}
+		query.Set(key, value)
+	}
 
-	u, err := url.ParseRequestURI(c.Endpoint + "/user_management/authorize")
+	u, err := url.ParseRequestURI(c.endpoint() + "/user_management/authorize")
 	if err != nil {
 		return nil, err
 	}
@@ -680,59 +1295,265 @@ func (c *Client) GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, er
 	return u, nil
 }
 
-// AuthenticateWithPassword authenticates a user with Email and Password
-func (c *Client) AuthenticateWithPassword(ctx context.Context, opts AuthenticateWithPasswordOpts) (AuthenticateResponse, error) {
-	payload := struct {
-		AuthenticateWithPasswordOpts
-		ClientSecret string `json:"client_secret"`
-		GrantType    string `json:"grant_type"`
-	}{
-		AuthenticateWithPasswordOpts: opts,
-		ClientSecret:                 c.APIKey,
-		GrantType:                    "password",
+func (c *Client) isAllowedRedirectURI(redirectURI string) bool {
+	if len(c.AllowedRedirectURIs) == 0 {
+		return true
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return AuthenticateResponse{}, err
+	for _, allowed := range c.AllowedRedirectURIs {
+		if allowed == redirectURI {
+			return true
+		}
 	}
+	return false
+}
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		c.Endpoint+"/user_management/authenticate",
-		bytes.NewBuffer(jsonData),
-	)
+// GetLogoutURLOpts contains the options to pass in order to generate a logout
+// url.
+type GetLogoutURLOpts struct {
+	// The ID of the Session to end, found in the sid claim of the access token.
+	//
+	// REQUIRED.
+	SessionID string
 
-	if err != nil {
-		return AuthenticateResponse{}, err
+	// The absolute URL where the User is redirected after logging out
+	// (eg. https://foo.com/). Defaults to the default logout URI configured
+	// in the WorkOS dashboard.
+	//
+	// OPTIONAL.
+	ReturnTo string
+}
+
+// GetLogoutURL generates the URL to redirect the User to in order to end their
+// Session.
+func (c *Client) GetLogoutURL(opts GetLogoutURLOpts) (*url.URL, error) {
+	if opts.SessionID == "" {
+		return nil, errors.New("incomplete arguments: missing SessionID")
 	}
 
-	// Add headers and context to the request
-	req = req.WithContext(ctx)
-	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
-	req.Header.Set("Content-Type", "application/json")
+	query := make(url.Values, 2)
+	query.Set("session_id", opts.SessionID)
 
-	// Execute the request
-	res, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return AuthenticateResponse{}, err
+	if opts.ReturnTo != "" {
+		returnTo, err := url.Parse(opts.ReturnTo)
+		if err != nil || !returnTo.IsAbs() || returnTo.Host == "" {
+			return nil, fmt.Errorf("invalid arguments: ReturnTo %q is not an absolute URL", opts.ReturnTo)
+		}
+		query.Set("return_to", opts.ReturnTo)
 	}
-	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
-		return AuthenticateResponse{}, err
+	u, err := url.ParseRequestURI(c.endpoint() + "/user_management/sessions/logout")
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse the JSON response
-	var body AuthenticateResponse
-	dec := json.NewDecoder(res.Body)
-	err = dec.Decode(&body)
+	u.RawQuery = query.Encode()
+	return u, nil
+}
 
-	return body, err
+// ErrMissingClientSecret is returned by the AuthenticateWith methods when the
+// client has no API key to send as the client secret.
+var ErrMissingClientSecret = errors.New("incomplete arguments: missing client secret, set the client's APIKey")
+
+// ErrorCodeAuthenticationRateLimit is the ErrorCode of the errors returned by
+// the AuthenticateWith methods when too many attempts failed.
+const ErrorCodeAuthenticationRateLimit = "authentication_rate_limit"
+
+// IsAuthenticationRateLimited reports whether err was returned because too many
+// authentication attempts failed. The returned duration is the delay after
+// which authentication can be attempted again, or zero when unknown.
+func IsAuthenticationRateLimited(err error) (time.Duration, bool) {
+	var httpError workos_errors.HTTPError
+	if !errors.As(err, &httpError) || httpError.ErrorCode != ErrorCodeAuthenticationRateLimit {
+		return 0, false
+	}
+	return httpError.RetryAfter, true
 }
 
-// AuthenticateWithCode authenticates an OAuth user or a managed SSO user that is logging in through SSO
-func (c *Client) AuthenticateWithCode(ctx context.Context, opts AuthenticateWithCodeOpts) (AuthenticateResponse, error) {
+// ProviderError is returned by AuthenticateWithCode when the OAuth or SSO
+// provider the User signed in with rejected the authentication, eg. because
+// the provider is not configured for the environment.
+type ProviderError struct {
+	// The provider that rejected the authentication, eg. "GoogleOAuth".
+	Provider string
+
+	// Why the provider rejected the authentication.
+	Reason string
+
+	// The underlying workos_errors.HTTPError.
+	Err error
+}
+
+func (e ProviderError) Error() string {
+	return fmt.Sprintf("provider %s: %s", e.Provider, e.Reason)
+}
+
+// Unwrap returns the underlying workos_errors.HTTPError.
+func (e ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// tryGetProviderError returns a ProviderError when the response is an error
+// naming the provider that caused it, and the error returned by
+// tryGetHTTPError otherwise.
+func (c *Client) tryGetProviderError(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	httpErr := c.tryGetHTTPError(res)
+
+	var payload struct {
+		Provider         string `json:"provider"`
+		Reason           string `json:"reason"`
+		ErrorDescription string `json:"error_description"`
+		Message          string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Provider == "" {
+		return httpErr
+	}
+
+	reason := payload.Reason
+	if reason == "" {
+		reason = payload.ErrorDescription
+	}
+	if reason == "" {
+		reason = payload.Message
+	}
+	return ProviderError{Provider: payload.Provider, Reason: reason, Err: httpErr}
+}
+
+// ErrorCodeOrganizationSelectionRequired is the ErrorCode of the errors
+// returned when the User must select the Organization to sign in to.
+const ErrorCodeOrganizationSelectionRequired = "organization_selection_required"
+
+// OrganizationSelectionRequiredError is returned by the AuthenticateWith
+// methods when the User is a member of several Organizations and must select
+// the one to sign in to. The selection is completed with
+// AuthenticateWithOrganizationSelection.
+type OrganizationSelectionRequiredError struct {
+	// The token to pass to AuthenticateWithOrganizationSelection.
+	PendingAuthenticationToken string
+
+	// The User who is authenticating.
+	User User
+
+	// The Organizations the User can select.
+	Organizations []OrganizationSummary
+
+	// The underlying workos_errors.HTTPError.
+	Err error
+}
+
+func (e OrganizationSelectionRequiredError) Error() string {
+	return fmt.Sprintf("organization selection required among %d organizations", len(e.Organizations))
+}
+
+// Unwrap returns the underlying workos_errors.HTTPError.
+func (e OrganizationSelectionRequiredError) Unwrap() error {
+	return e.Err
+}
+
+// tryGetAuthenticationError returns an OrganizationSelectionRequiredError when
+// the response requires the User to select an Organization, and the error
+// returned by tryGetProviderError otherwise.
+func (c *Client) tryGetAuthenticationError(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		Code                       string                `json:"code"`
+		PendingAuthenticationToken string                `json:"pending_authentication_token"`
+		User                       User                  `json:"user"`
+		Organizations              []OrganizationSummary `json:"organizations"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Code != ErrorCodeOrganizationSelectionRequired {
+		return c.tryGetProviderError(res)
+	}
+
+	return OrganizationSelectionRequiredError{
+		PendingAuthenticationToken: payload.PendingAuthenticationToken,
+		User:                       payload.User,
+		Organizations:              payload.Organizations,
+		Err:                        c.tryGetHTTPError(res),
+	}
+}
+
+// AuthenticateWithPassword authenticates a user with Email and Password
+func (c *Client) AuthenticateWithPassword(ctx context.Context, opts AuthenticateWithPasswordOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+
+	payload := struct {
+		AuthenticateWithPasswordOpts
+		ClientSecret string `json:"client_secret"`
+		GrantType    string `json:"grant_type"`
+	}{
+		AuthenticateWithPasswordOpts: opts,
+		ClientSecret:                 c.APIKey,
+		GrantType:                    "password",
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return AuthenticateResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.endpoint()+"/user_management/authenticate",
+		bytes.NewBuffer(jsonData),
+	)
+
+	if err != nil {
+		return AuthenticateResponse{}, err
+	}
+
+	// Add headers and context to the request
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute the request
+	res, err := c.do(req)
+	if err != nil {
+		return AuthenticateResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetAuthenticationError(res); err != nil {
+		return AuthenticateResponse{}, err
+	}
+
+	// Parse the JSON response
+	return c.decodeAuthenticateResponse(res.Body)
+}
+
+// AuthenticateWithCode authenticates an OAuth user or a managed SSO user that is logging in through SSO
+func (c *Client) AuthenticateWithCode(ctx context.Context, opts AuthenticateWithCodeOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+	if opts.CodeVerifier != "" {
+		if err := validateCodeVerifier(opts.CodeVerifier); err != nil {
+			return AuthenticateResponse{}, err
+		}
+	}
+
 	payload := struct {
 		AuthenticateWithCodeOpts
 		ClientSecret string `json:"client_secret"`
@@ -748,9 +1569,10 @@ func (c *Client) AuthenticateWithCode(ctx context.Context, opts AuthenticateWith
 		return AuthenticateResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
-		c.Endpoint+"/user_management/authenticate",
+		c.endpoint()+"/user_management/authenticate",
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -759,32 +1581,36 @@ func (c *Client) AuthenticateWithCode(ctx context.Context, opts AuthenticateWith
 	}
 
 	// Add headers and context to the request
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute the request
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuthenticateResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetAuthenticationError(res); err != nil {
 		return AuthenticateResponse{}, err
 	}
 
 	// Parse the JSON response
-	var body AuthenticateResponse
-	dec := json.NewDecoder(res.Body)
-	err = dec.Decode(&body)
-
-	return body, err
+	return c.decodeAuthenticateResponse(res.Body)
 }
 
 // AuthenticateWithMagicAuth authenticates a user by verifying a one-time code sent to the user's email address by
 // the Magic Auth Send Code endpoint.
+//
+// WorkOS has no endpoint checking a Magic Auth code without consuming it, so
+// there is no separate verify step: authenticating is the only way to know
+// whether a code is valid. Invalid or expired codes are rejected with a
+// workos_errors.HTTPError with a 400 status.
 func (c *Client) AuthenticateWithMagicAuth(ctx context.Context, opts AuthenticateWithMagicAuthOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+
 	payload := struct {
 		AuthenticateWithMagicAuthOpts
 		ClientSecret string `json:"client_secret"`
@@ -800,9 +1626,10 @@ func (c *Client) AuthenticateWithMagicAuth(ctx context.Context, opts Authenticat
 		return AuthenticateResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
-		c.Endpoint+"/user_management/authenticate",
+		c.endpoint()+"/user_management/authenticate",
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -811,31 +1638,30 @@ func (c *Client) AuthenticateWithMagicAuth(ctx context.Context, opts Authenticat
 	}
 
 	// Add headers and context to the request
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute the request
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuthenticateResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetAuthenticationError(res); err != nil {
 		return AuthenticateResponse{}, err
 	}
 
 	// Parse the JSON response
-	var body AuthenticateResponse
-	dec := json.NewDecoder(res.Body)
-	err = dec.Decode(&body)
-
-	return body, err
+	return c.decodeAuthenticateResponse(res.Body)
 }
 
 // AuthenticateWithTOTP authenticates a user by verifying a time-based one-time password (TOTP)
 func (c *Client) AuthenticateWithTOTP(ctx context.Context, opts AuthenticateWithTOTPOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+
 	payload := struct {
 		AuthenticateWithTOTPOpts
 		ClientSecret string `json:"client_secret"`
@@ -851,9 +1677,10 @@ func (c *Client) AuthenticateWithTOTP(ctx context.Context, opts AuthenticateWith
 		return AuthenticateResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
-		c.Endpoint+"/user_management/authenticate",
+		c.endpoint()+"/user_management/authenticate",
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -862,31 +1689,30 @@ func (c *Client) AuthenticateWithTOTP(ctx context.Context, opts AuthenticateWith
 	}
 
 	// Add headers and context to the request
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute the request
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuthenticateResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetAuthenticationError(res); err != nil {
 		return AuthenticateResponse{}, err
 	}
 
 	// Parse the JSON response
-	var body AuthenticateResponse
-	dec := json.NewDecoder(res.Body)
-	err = dec.Decode(&body)
-
-	return body, err
+	return c.decodeAuthenticateResponse(res.Body)
 }
 
 // AuthenticateWithEmailVerificationCode authenticates a user by verifying a code sent to their email address
 func (c *Client) AuthenticateWithEmailVerificationCode(ctx context.Context, opts AuthenticateWithEmailVerificationCodeOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+
 	payload := struct {
 		AuthenticateWithEmailVerificationCodeOpts
 		ClientSecret string `json:"client_secret"`
@@ -902,9 +1728,10 @@ func (c *Client) AuthenticateWithEmailVerificationCode(ctx context.Context, opts
 		return AuthenticateResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
-		c.Endpoint+"/user_management/authenticate",
+		c.endpoint()+"/user_management/authenticate",
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -913,31 +1740,39 @@ func (c *Client) AuthenticateWithEmailVerificationCode(ctx context.Context, opts
 	}
 
 	// Add headers and context to the request
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute the request
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuthenticateResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetAuthenticationError(res); err != nil {
 		return AuthenticateResponse{}, err
 	}
 
 	// Parse the JSON response
-	var body AuthenticateResponse
-	dec := json.NewDecoder(res.Body)
-	err = dec.Decode(&body)
-
-	return body, err
+	return c.decodeAuthenticateResponse(res.Body)
 }
 
 // AuthenticateWithOrganizationSelection completes authentication for a user given an organization they've selected.
+// The PendingAuthenticationToken and the selectable Organizations are found in
+// the OrganizationSelectionRequiredError returned by the other AuthenticateWith
+// methods.
 func (c *Client) AuthenticateWithOrganizationSelection(ctx context.Context, opts AuthenticateWithOrganizationSelectionOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+	if opts.PendingAuthenticationToken == "" {
+		return AuthenticateResponse{}, errors.New("incomplete arguments: missing PendingAuthenticationToken")
+	}
+	if opts.OrganizationID == "" {
+		return AuthenticateResponse{}, errors.New("incomplete arguments: missing OrganizationID")
+	}
+
 	payload := struct {
 		AuthenticateWithOrganizationSelectionOpts
 		ClientSecret string `json:"client_secret"`
@@ -953,9 +1788,10 @@ func (c *Client) AuthenticateWithOrganizationSelection(ctx context.Context, opts
 		return AuthenticateResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
-		c.Endpoint+"/user_management/authenticate",
+		c.endpoint()+"/user_management/authenticate",
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -964,37 +1800,85 @@ func (c *Client) AuthenticateWithOrganizationSelection(ctx context.Context, opts
 	}
 
 	// Add headers and context to the request
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute the request
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return AuthenticateResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return AuthenticateResponse{}, err
 	}
 
 	// Parse the JSON response
-	var body AuthenticateResponse
-	dec := json.NewDecoder(res.Body)
-	err = dec.Decode(&body)
+	return c.decodeAuthenticateResponse(res.Body)
+}
 
-	return body, err
+// AuthenticateWithRefreshToken exchanges a refresh token for a new access token
+// and refresh token.
+func (c *Client) AuthenticateWithRefreshToken(ctx context.Context, opts AuthenticateWithRefreshTokenOpts) (AuthenticateResponse, error) {
+	if c.APIKey == "" {
+		return AuthenticateResponse{}, ErrMissingClientSecret
+	}
+
+	payload := struct {
+		AuthenticateWithRefreshTokenOpts
+		ClientSecret string `json:"client_secret"`
+		GrantType    string `json:"grant_type"`
+	}{
+		AuthenticateWithRefreshTokenOpts: opts,
+		ClientSecret:                     c.APIKey,
+		GrantType:                        "refresh_token",
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return AuthenticateResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.endpoint()+"/user_management/authenticate",
+		bytes.NewBuffer(jsonData),
+	)
+
+	if err != nil {
+		return AuthenticateResponse{}, err
+	}
+
+	// Add headers and context to the request
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute the request
+	res, err := c.do(req)
+	if err != nil {
+		return AuthenticateResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return AuthenticateResponse{}, err
+	}
+
+	// Parse the JSON response
+	return c.decodeAuthenticateResponse(res.Body)
 }
 
 // SendVerificationEmail creates an email verification challenge and emails verification token to user.
 func (c *Client) SendVerificationEmail(ctx context.Context, opts SendVerificationEmailOpts) (UserResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s/email_verification/send",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		nil,
@@ -1002,18 +1886,17 @@ func (c *Client) SendVerificationEmail(ctx context.Context, opts SendVerificatio
 	if err != nil {
 		return UserResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return UserResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return UserResponse{}, err
 	}
 
@@ -1028,7 +1911,7 @@ func (c *Client) SendVerificationEmail(ctx context.Context, opts SendVerificatio
 func (c *Client) VerifyEmail(ctx context.Context, opts VerifyEmailOpts) (UserResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s/email_verification/confirm",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
 
@@ -1037,7 +1920,8 @@ func (c *Client) VerifyEmail(ctx context.Context, opts VerifyEmailOpts) (UserRes
 		return UserResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1045,18 +1929,17 @@ func (c *Client) VerifyEmail(ctx context.Context, opts VerifyEmailOpts) (UserRes
 	if err != nil {
 		return UserResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return UserResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return UserResponse{}, err
 	}
 
@@ -1072,7 +1955,7 @@ func (c *Client) VerifyEmail(ctx context.Context, opts VerifyEmailOpts) (UserRes
 func (c *Client) SendPasswordResetEmail(ctx context.Context, opts SendPasswordResetEmailOpts) error {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/password_reset/send",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
 	data, err := c.JSONEncode(opts)
@@ -1080,7 +1963,8 @@ func (c *Client) SendPasswordResetEmail(ctx context.Context, opts SendPasswordRe
 		return err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1088,25 +1972,31 @@ func (c *Client) SendPasswordResetEmail(ctx context.Context, opts SendPasswordRe
 	if err != nil {
 		return err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
 
 // ResetPassword resets user password using token that was sent to the user.
 func (c *Client) ResetPassword(ctx context.Context, opts ResetPasswordOpts) (UserResponse, error) {
+	if opts.Token == "" {
+		return UserResponse{}, errors.New("incomplete arguments: missing Token")
+	}
+	if opts.NewPassword == "" {
+		return UserResponse{}, errors.New("incomplete arguments: missing NewPassword")
+	}
+
 	endpoint := fmt.Sprintf(
 		"%s/user_management/password_reset/confirm",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
 	data, err := c.JSONEncode(opts)
@@ -1114,7 +2004,8 @@ func (c *Client) ResetPassword(ctx context.Context, opts ResetPasswordOpts) (Use
 		return UserResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1122,18 +2013,17 @@ func (c *Client) ResetPassword(ctx context.Context, opts ResetPasswordOpts) (Use
 	if err != nil {
 		return UserResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return UserResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return UserResponse{}, err
 	}
 
@@ -1144,11 +2034,62 @@ func (c *Client) ResetPassword(ctx context.Context, opts ResetPasswordOpts) (Use
 	return body, err
 }
 
+// ErrMagicAuthThrottled is returned by SendMagicAuthCode when a code was
+// already sent to the same email within the client's MagicAuthThrottle window.
+var ErrMagicAuthThrottled = errors.New("magic auth code was sent too recently")
+
 // SendMagicAuthCode creates a one-time Magic Auth code and emails it to the user.
 func (c *Client) SendMagicAuthCode(ctx context.Context, opts SendMagicAuthCodeOpts) error {
+	if c.MagicAuthThrottle <= 0 {
+		return c.sendMagicAuthCode(ctx, opts)
+	}
+
+	key := strings.ToLower(opts.Email)
+	sentAt, ok := c.reserveMagicAuth(key)
+	if !ok {
+		return ErrMagicAuthThrottled
+	}
+
+	if err := c.sendMagicAuthCode(ctx, opts); err != nil {
+		c.magicAuthMu.Lock()
+		if c.magicAuthSentAt[key] == sentAt {
+			delete(c.magicAuthSentAt, key)
+		}
+		c.magicAuthMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// reserveMagicAuth records a Magic Auth code as sent to the given email now,
+// unless one was already sent within MagicAuthThrottle, and returns the time
+// it was recorded at. The records older than MagicAuthThrottle are pruned.
+func (c *Client) reserveMagicAuth(key string) (time.Time, bool) {
+	c.magicAuthMu.Lock()
+	defer c.magicAuthMu.Unlock()
+
+	now := c.now()
+	if sentAt, ok := c.magicAuthSentAt[key]; ok && now.Sub(sentAt) < c.MagicAuthThrottle {
+		return time.Time{}, false
+	}
+
+	if c.magicAuthSentAt == nil {
+		c.magicAuthSentAt = make(map[string]time.Time)
+	}
+	for email, sentAt := range c.magicAuthSentAt {
+		if now.Sub(sentAt) >= c.MagicAuthThrottle {
+			delete(c.magicAuthSentAt, email)
+		}
+	}
+
+	c.magicAuthSentAt[key] = now
+	return now, true
+}
+
+func (c *Client) sendMagicAuthCode(ctx context.Context, opts SendMagicAuthCodeOpts) error {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/magic_auth/send",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
 	data, err := c.JSONEncode(opts)
@@ -1156,7 +2097,8 @@ func (c *Client) SendMagicAuthCode(ctx context.Context, opts SendMagicAuthCodeOp
 		return err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1164,25 +2106,24 @@ func (c *Client) SendMagicAuthCode(ctx context.Context, opts SendMagicAuthCodeOp
 	if err != nil {
 		return err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
 
 // EnrollAuthFactor enrolls an authentication factor for the user.
 func (c *Client) EnrollAuthFactor(ctx context.Context, opts EnrollAuthFactorOpts) (EnrollAuthFactorResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s/auth_factors",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
 
@@ -1191,7 +2132,8 @@ func (c *Client) EnrollAuthFactor(ctx context.Context, opts EnrollAuthFactorOpts
 		return EnrollAuthFactorResponse{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1199,18 +2141,17 @@ func (c *Client) EnrollAuthFactor(ctx context.Context, opts EnrollAuthFactorOpts
 	if err != nil {
 		return EnrollAuthFactorResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return EnrollAuthFactorResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return EnrollAuthFactorResponse{}, err
 	}
 
@@ -1225,11 +2166,12 @@ func (c *Client) EnrollAuthFactor(ctx context.Context, opts EnrollAuthFactorOpts
 func (c *Client) ListAuthFactors(ctx context.Context, opts ListAuthFactorsOpts) (ListAuthFactorsResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/users/%s/auth_factors",
-		c.Endpoint,
+		c.endpoint(),
 		opts.User,
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -1237,18 +2179,17 @@ func (c *Client) ListAuthFactors(ctx context.Context, opts ListAuthFactorsOpts)
 	if err != nil {
 		return ListAuthFactorsResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListAuthFactorsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListAuthFactorsResponse{}, err
 	}
 
@@ -1263,11 +2204,12 @@ func (c *Client) ListAuthFactors(ctx context.Context, opts ListAuthFactorsOpts)
 func (c *Client) GetOrganizationMembership(ctx context.Context, opts GetOrganizationMembershipOpts) (OrganizationMembership, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/organization_memberships/%s",
-		c.Endpoint,
+		c.endpoint(),
 		opts.OrganizationMembership,
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -1275,23 +2217,96 @@ func (c *Client) GetOrganizationMembership(ctx context.Context, opts GetOrganiza
 	if err != nil {
 		return OrganizationMembership{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return OrganizationMembership{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return OrganizationMembership{}, err
 	}
 
 	var body OrganizationMembership
 	dec := json.NewDecoder(res.Body)
+	if err = dec.Decode(&body); err != nil {
+		return OrganizationMembership{}, err
+	}
+
+	for _, expansion := range opts.Expand {
+		if expansion == ExpandRolePermissions {
+			if body.Role, err = c.expandRolePermissions(ctx, body.OrganizationID, body.Role); err != nil {
+				return OrganizationMembership{}, err
+			}
+		}
+	}
+
+	return body, nil
+}
+
+// expandRolePermissions returns the role with the permissions of the matching
+// role of the Organization. Like ListOrganizationMembersWithRoles, it falls back
+// to the membership's own role when the role is not listed, eg. because it was
+// deleted or renamed in the meantime.
+func (c *Client) expandRolePermissions(ctx context.Context, organizationID string, role RoleResponse) (RoleResponse, error) {
+	roles, err := c.ListOrganizationRoles(ctx, ListOrganizationRolesOpts{
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		return RoleResponse{}, err
+	}
+
+	for _, r := range roles.Data {
+		if r.Slug == role.Slug {
+			role.Permissions = r.Permissions
+			return role, nil
+		}
+	}
+	return role, nil
+}
+
+// ListOrganizationRoles gets the Roles that can be assigned to the members of
+// an Organization.
+func (c *Client) ListOrganizationRoles(ctx context.Context, opts ListOrganizationRolesOpts) (ListOrganizationRolesResponse, error) {
+	if opts.OrganizationID == "" {
+		return ListOrganizationRolesResponse{}, errors.New("incomplete arguments: missing OrganizationID")
+	}
+
+	endpoint := fmt.Sprintf(
+		"%s/organizations/%s/roles",
+		c.endpoint(),
+		opts.OrganizationID,
+	)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		endpoint,
+		nil,
+	)
+	if err != nil {
+		return ListOrganizationRolesResponse{}, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return ListOrganizationRolesResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return ListOrganizationRolesResponse{}, err
+	}
+
+	var body ListOrganizationRolesResponse
+	dec := json.NewDecoder(res.Body)
 	err = dec.Decode(&body)
 
 	return body, err
@@ -1301,10 +2316,11 @@ func (c *Client) GetOrganizationMembership(ctx context.Context, opts GetOrganiza
 func (c *Client) ListOrganizationMemberships(ctx context.Context, opts ListOrganizationMembershipsOpts) (ListOrganizationMembershipsResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/organization_memberships",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -1312,7 +2328,6 @@ func (c *Client) ListOrganizationMemberships(ctx context.Context, opts ListOrgan
 	if err != nil {
 		return ListOrganizationMembershipsResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
@@ -1328,28 +2343,259 @@ func (c *Client) ListOrganizationMemberships(ctx context.Context, opts ListOrgan
 
 	req.URL.RawQuery = queryValues.Encode()
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListOrganizationMembershipsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListOrganizationMembershipsResponse{}, err
 	}
 
 	var body ListOrganizationMembershipsResponse
 	dec := json.NewDecoder(res.Body)
+	if err = dec.Decode(&body); err != nil {
+		return body, err
+	}
+
+	if opts.RoleSlug != "" {
+		memberships := make([]OrganizationMembership, 0, len(body.Data))
+		for _, membership := range body.Data {
+			if membership.Role.Slug == opts.RoleSlug {
+				memberships = append(memberships, membership)
+			}
+		}
+		body.Data = memberships
+	}
+	return body, nil
+}
+
+// listAllOrganizationMemberships returns every Organization Membership matching
+// the given options, following pagination cursors until the last page.
+func (c *Client) listAllOrganizationMemberships(ctx context.Context, opts ListOrganizationMembershipsOpts) ([]OrganizationMembership, error) {
+	var memberships []OrganizationMembership
+
+	err := common.Paginate(ctx, func(ctx context.Context, after string) (string, error) {
+		opts.After = after
+
+		res, err := c.ListOrganizationMemberships(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		memberships = append(memberships, res.Data...)
+		return res.ListMetadata.After, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// findOrganizationMembership gets the Organization Membership of a User in an
+// Organization, whatever its status. found is false when the User is not a
+// member of the Organization.
+func (c *Client) findOrganizationMembership(ctx context.Context, userID string, organizationID string) (membership OrganizationMembership, found bool, err error) {
+	res, err := c.ListOrganizationMemberships(ctx, ListOrganizationMembershipsOpts{
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Limit:          1,
+	})
+	if err != nil || len(res.Data) == 0 {
+		return OrganizationMembership{}, false, err
+	}
+	return res.Data[0], true, nil
+}
+
+// IsActiveMember reports whether a User has an active Organization Membership
+// in an Organization, eg. to authorize a request. Inactive and pending
+// memberships are not considered, nor is a membership without status.
+func (c *Client) IsActiveMember(ctx context.Context, userID string, organizationID string) (bool, error) {
+	if userID == "" {
+		return false, errors.New("incomplete arguments: missing UserID")
+	}
+	if organizationID == "" {
+		return false, errors.New("incomplete arguments: missing OrganizationID")
+	}
+
+	membership, found, err := c.findOrganizationMembership(ctx, userID, organizationID)
+	if err != nil || !found {
+		return false, err
+	}
+	return membership.Status == OrganizationMembershipActive, nil
+}
+
+// ListOrganizationMembersWithRoles gets every Organization Membership of an
+// Organization, following pagination cursors, along with the Role of each
+// member, eg. to display the members of an Organization.
+//
+// A member whose role is not listed by ListOrganizationRoles, eg. because it
+// was deleted or renamed in the meantime, gets a Role built from the
+// membership's own role, holding only its slug and permissions.
+func (c *Client) ListOrganizationMembersWithRoles(ctx context.Context, organizationID string) ([]MembershipWithRole, error) {
+	if organizationID == "" {
+		return nil, errors.New("incomplete arguments: missing OrganizationID")
+	}
+
+	memberships, err := c.listAllOrganizationMemberships(ctx, ListOrganizationMembershipsOpts{
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := c.ListOrganizationRoles(ctx, ListOrganizationRolesOpts{
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rolesBySlug := make(map[string]Role, len(roles.Data))
+	for _, role := range roles.Data {
+		rolesBySlug[role.Slug] = role
+	}
+
+	members := make([]MembershipWithRole, 0, len(memberships))
+	for _, membership := range memberships {
+		role, ok := rolesBySlug[membership.Role.Slug]
+		if !ok {
+			role = Role{Slug: membership.Role.Slug, Permissions: membership.Role.Permissions}
+		}
+		members = append(members, MembershipWithRole{Membership: membership, Role: role})
+	}
+	return members, nil
+}
+
+// ListOrganizationMembershipsByUser lists the Organization Memberships of each
+// of the given Users, grouped by User ID. The other filters of opts, such as
+// OrganizationID, are applied to every User.
+func (c *Client) ListOrganizationMembershipsByUser(
+	ctx context.Context,
+	userIDs []string,
+	opts ListOrganizationMembershipsOpts,
+) (map[string][]OrganizationMembership, error) {
+	memberships := make(map[string][]OrganizationMembership, len(userIDs))
+
+	for _, userID := range userIDs {
+		opts.UserID = userID
+		opts.After = ""
+
+		userMemberships, err := c.listAllOrganizationMemberships(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		memberships[userID] = userMemberships
+	}
+
+	return memberships, nil
+}
+
+// ListUserOrganizations gets the Organizations a User is a member of, eg. to
+// let the User pick the Organization to sign in to. Organizations are returned
+// in the order of the User's Organization Memberships.
+//
+// Only the Organizations of active memberships are returned. Use
+// ListUserOrganizationsWithOpts to include the other memberships.
+func (c *Client) ListUserOrganizations(ctx context.Context, userID string) ([]OrganizationSummary, error) {
+	return c.ListUserOrganizationsWithOpts(ctx, userID, ListUserOrganizationsOpts{})
+}
+
+// ListUserOrganizationsOpts contains the options of
+// ListUserOrganizationsWithOpts.
+type ListUserOrganizationsOpts struct {
+	// Whether to also return the Organizations of inactive and pending
+	// memberships.
+	//
+	// OPTIONAL.
+	IncludeInactive bool
+}
+
+// ListUserOrganizationsWithOpts gets the Organizations a User is a member of,
+// like ListUserOrganizations, with the given options.
+func (c *Client) ListUserOrganizationsWithOpts(ctx context.Context, userID string, opts ListUserOrganizationsOpts) ([]OrganizationSummary, error) {
+	if userID == "" {
+		return nil, errors.New("incomplete arguments: missing UserID")
+	}
+
+	statuses := []OrganizationMembershipStatus{OrganizationMembershipActive}
+	if opts.IncludeInactive {
+		statuses = append(statuses, OrganizationMembershipInactive, OrganizationMembershipPending)
+	}
+
+	memberships, err := c.listAllOrganizationMemberships(ctx, ListOrganizationMembershipsOpts{
+		UserID:   userID,
+		Statuses: statuses,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]OrganizationSummary, 0, len(memberships))
+	seen := make(map[string]bool, len(memberships))
+
+	for _, membership := range memberships {
+		if seen[membership.OrganizationID] {
+			continue
+		}
+		seen[membership.OrganizationID] = true
+
+		organization, err := c.getOrganizationSummary(ctx, membership.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, organization)
+	}
+
+	return organizations, nil
+}
+
+// getOrganizationSummary gets the ID and name of an Organization.
+func (c *Client) getOrganizationSummary(ctx context.Context, organizationID string) (OrganizationSummary, error) {
+	endpoint := fmt.Sprintf(
+		"%s/organizations/%s",
+		c.endpoint(),
+		organizationID,
+	)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		endpoint,
+		nil,
+	)
+	if err != nil {
+		return OrganizationSummary{}, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return OrganizationSummary{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return OrganizationSummary{}, err
+	}
+
+	var body OrganizationSummary
+	dec := json.NewDecoder(res.Body)
 	err = dec.Decode(&body)
 
 	return body, err
 }
 
 // Create an Organization Membership. Adds a User to an Organization.
+//
+// The returned membership carries the effective role of the User, which is the
+// Organization's default role when opts.RoleSlug is empty.
 func (c *Client) CreateOrganizationMembership(ctx context.Context, opts CreateOrganizationMembershipOpts) (OrganizationMembership, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/organization_memberships",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
 	data, err := c.JSONEncode(opts)
@@ -1357,7 +2603,8 @@ func (c *Client) CreateOrganizationMembership(ctx context.Context, opts CreateOr
 		return OrganizationMembership{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1365,18 +2612,17 @@ func (c *Client) CreateOrganizationMembership(ctx context.Context, opts CreateOr
 	if err != nil {
 		return OrganizationMembership{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return OrganizationMembership{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return OrganizationMembership{}, err
 	}
 
@@ -1387,15 +2633,89 @@ func (c *Client) CreateOrganizationMembership(ctx context.Context, opts CreateOr
 	return body, err
 }
 
+// UpdateOrganizationMembership updates the role of an Organization Membership.
+//
+// It is idempotent: granting the role the membership already has succeeds, so
+// that reconcilers can set roles unconditionally.
+func (c *Client) UpdateOrganizationMembership(ctx context.Context, opts UpdateOrganizationMembershipOpts) (OrganizationMembership, error) {
+	if opts.OrganizationMembership == "" {
+		return OrganizationMembership{}, errors.New("incomplete arguments: missing OrganizationMembership")
+	}
+	if opts.RoleSlug == "" {
+		return OrganizationMembership{}, errors.New("incomplete arguments: missing RoleSlug")
+	}
+
+	endpoint := fmt.Sprintf(
+		"%s/user_management/organization_memberships/%s",
+		c.endpoint(),
+		opts.OrganizationMembership,
+	)
+
+	data, err := c.JSONEncode(opts)
+	if err != nil {
+		return OrganizationMembership{}, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPut,
+		endpoint,
+		bytes.NewBuffer(data),
+	)
+	if err != nil {
+		return OrganizationMembership{}, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return OrganizationMembership{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		if res.StatusCode == http.StatusConflict {
+			return c.unchangedOrganizationMembership(ctx, opts, err)
+		}
+		return OrganizationMembership{}, err
+	}
+
+	var body OrganizationMembership
+	dec := json.NewDecoder(res.Body)
+	err = dec.Decode(&body)
+
+	return body, err
+}
+
+// unchangedOrganizationMembership returns the membership when it already has
+// the requested role, in which case the conflict returned by the update is a
+// no-op, and the conflict otherwise.
+func (c *Client) unchangedOrganizationMembership(
+	ctx context.Context,
+	opts UpdateOrganizationMembershipOpts,
+	conflict error,
+) (OrganizationMembership, error) {
+	membership, err := c.GetOrganizationMembership(ctx, GetOrganizationMembershipOpts{
+		OrganizationMembership: opts.OrganizationMembership,
+	})
+	if err != nil || membership.Role.Slug != opts.RoleSlug {
+		return OrganizationMembership{}, conflict
+	}
+	return membership, nil
+}
+
 // Delete an Organization Membership. Removes the membership's User from its Organization.
 func (c *Client) DeleteOrganizationMembership(ctx context.Context, opts DeleteOrganizationMembershipOpts) error {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/organization_memberships/%s",
-		c.Endpoint,
+		c.endpoint(),
 		opts.OrganizationMembership,
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodDelete,
 		endpoint,
 		nil,
@@ -1403,40 +2723,79 @@ func (c *Client) DeleteOrganizationMembership(ctx context.Context, opts DeleteOr
 	if err != nil {
 		return err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return workos_errors.TryGetHTTPError(res)
+	return c.tryGetHTTPError(res)
 }
 
 // GetInvitation fetches an Invitation by its ID.
 func (c *Client) GetInvitation(ctx context.Context, opts GetInvitationOpts) (Invitation, error) {
-	endpoint := fmt.Sprintf("%s/user_management/invitations/%s", c.Endpoint, opts.Invitation)
+	endpoint := fmt.Sprintf("%s/user_management/invitations/%s", c.endpoint(), opts.Invitation)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Invitation{}, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return Invitation{}, err
+	}
+
+	var body Invitation
+	dec := json.NewDecoder(res.Body)
+	err = dec.Decode(&body)
+
+	return body, err
+}
+
+// FindInvitationByToken fetches an Invitation by its token.
+//
+// Invitation tokens are opaque strings rather than signed tokens, and their
+// format is not documented, so they cannot be validated offline: whether a
+// token is unknown, expired, accepted or revoked is only told by the API.
+func (c *Client) FindInvitationByToken(ctx context.Context, opts FindInvitationByTokenOpts) (Invitation, error) {
+	if opts.InvitationToken == "" {
+		return Invitation{}, ErrMissingInvitationToken
+	}
 
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	endpoint := fmt.Sprintf(
+		"%s/user_management/invitations/by_token/%s",
+		c.endpoint(),
+		url.PathEscape(opts.InvitationToken),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return Invitation{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Invitation{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Invitation{}, err
 	}
 
@@ -1447,14 +2806,33 @@ func (c *Client) GetInvitation(ctx context.Context, opts GetInvitationOpts) (Inv
 	return body, err
 }
 
+// ErrMissingInvitationToken is returned when looking up an Invitation without
+// its token.
+var ErrMissingInvitationToken = errors.New("incomplete arguments: missing invitation token")
+
+// InvitationTokenParam is the query parameter carrying the invitation token on
+// the invitation acceptance page.
+const InvitationTokenParam = "invitation_token"
+
+// InvitationFromRequest fetches the Invitation whose token is carried by the
+// invitation_token query parameter of the request, eg. to render the page
+// where the invitation is accepted. It returns ErrMissingInvitationToken when
+// the parameter is not set.
+func (c *Client) InvitationFromRequest(r *http.Request) (Invitation, error) {
+	return c.FindInvitationByToken(r.Context(), FindInvitationByTokenOpts{
+		InvitationToken: r.URL.Query().Get(InvitationTokenParam),
+	})
+}
+
 // ListInvitations gets a list of all of your existing Invitations matching the criteria specified.
 func (c *Client) ListInvitations(ctx context.Context, opts ListInvitationsOpts) (ListInvitationsResponse, error) {
 	endpoint := fmt.Sprintf(
 		"%s/user_management/invitations",
-		c.Endpoint,
+		c.endpoint(),
 	)
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		endpoint,
 		nil,
@@ -1462,7 +2840,6 @@ func (c *Client) ListInvitations(ctx context.Context, opts ListInvitationsOpts)
 	if err != nil {
 		return ListInvitationsResponse{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
@@ -1478,13 +2855,13 @@ func (c *Client) ListInvitations(ctx context.Context, opts ListInvitationsOpts)
 
 	req.URL.RawQuery = queryValues.Encode()
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return ListInvitationsResponse{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return ListInvitationsResponse{}, err
 	}
 
@@ -1495,15 +2872,56 @@ func (c *Client) ListInvitations(ctx context.Context, opts ListInvitationsOpts)
 	return body, err
 }
 
+// ListPendingInvitations gets all the Invitations of an Organization that are
+// still pending, going through every page of results.
+func (c *Client) ListPendingInvitations(ctx context.Context, organizationID string) ([]Invitation, error) {
+	if organizationID == "" {
+		return nil, errors.New("incomplete arguments: missing OrganizationID")
+	}
+
+	var invitations []Invitation
+
+	err := common.Paginate(ctx, func(ctx context.Context, after string) (string, error) {
+		res, err := c.ListInvitations(ctx, ListInvitationsOpts{
+			OrganizationID: organizationID,
+			After:          after,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		for _, invitation := range res.Data {
+			if invitation.State == Pending {
+				invitations = append(invitations, invitation)
+			}
+		}
+		return res.ListMetadata.After, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
 func (c *Client) SendInvitation(ctx context.Context, opts SendInvitationOpts) (Invitation, error) {
-	endpoint := fmt.Sprintf("%s/user_management/invitations", c.Endpoint)
+	if opts.ExpiresInDays != 0 && (opts.ExpiresInDays < MinInvitationExpiresInDays || opts.ExpiresInDays > MaxInvitationExpiresInDays) {
+		return Invitation{}, fmt.Errorf(
+			"invalid arguments: ExpiresInDays must be between %d and %d, got %d",
+			MinInvitationExpiresInDays,
+			MaxInvitationExpiresInDays,
+			opts.ExpiresInDays,
+		)
+	}
+
+	endpoint := fmt.Sprintf("%s/user_management/invitations", c.endpoint())
 
 	data, err := json.Marshal(opts)
 	if err != nil {
 		return Invitation{}, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		endpoint,
 		bytes.NewBuffer(data),
@@ -1511,18 +2929,17 @@ func (c *Client) SendInvitation(ctx context.Context, opts SendInvitationOpts) (I
 	if err != nil {
 		return Invitation{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Invitation{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
 		return Invitation{}, err
 	}
 
@@ -1534,24 +2951,135 @@ func (c *Client) SendInvitation(ctx context.Context, opts SendInvitationOpts) (I
 }
 
 func (c *Client) RevokeInvitation(ctx context.Context, opts RevokeInvitationOpts) (Invitation, error) {
-	endpoint := fmt.Sprintf("%s/user_management/invitations/%s/revoke", c.Endpoint, opts.Invitation)
+	endpoint := fmt.Sprintf("%s/user_management/invitations/%s/revoke", c.endpoint(), opts.Invitation)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return Invitation{}, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return Invitation{}, err
+	}
+
+	var body Invitation
+	dec := json.NewDecoder(res.Body)
+	err = dec.Decode(&body)
+
+	return body, err
+}
+
+// ResendInvitation sends the email of a pending Invitation again, eg. when the
+// invited User lost it. The Invitation is resent through the API's resend
+// endpoint, so it keeps its ID, token and metadata, unlike revoking it and
+// sending a new one.
+func (c *Client) ResendInvitation(ctx context.Context, opts ResendInvitationOpts) (Invitation, error) {
+	if opts.Invitation == "" {
+		return Invitation{}, errors.New("incomplete arguments: missing Invitation")
+	}
+
+	endpoint := fmt.Sprintf("%s/user_management/invitations/%s/resend", c.endpoint(), opts.Invitation)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return Invitation{}, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return Invitation{}, err
+	}
+
+	var body Invitation
+	dec := json.NewDecoder(res.Body)
+	err = dec.Decode(&body)
+
+	return body, err
+}
+
+// This represents the list of errors that could be returned by AcceptInvitation
+// when the Invitation cannot be accepted anymore. The returned errors still
+// wrap the workos_errors.HTTPError of the response.
+var (
+	ErrInvitationNotPending = errors.New("invitation is not pending, it was already accepted or revoked")
+	ErrInvitationExpired    = errors.New("invitation is expired")
+)
+
+// invitationErrors maps the error codes of the API to the errors returned by
+// AcceptInvitation.
+var invitationErrors = map[string]error{
+	"invitation_not_pending": ErrInvitationNotPending,
+	"invitation_expired":     ErrInvitationExpired,
+}
+
+// invitationError is an HTTP error that matches one of the invitation errors
+// with errors.Is.
+type invitationError struct {
+	err      error
+	sentinel error
+}
+
+func (e invitationError) Error() string {
+	return e.err.Error()
+}
+
+func (e invitationError) Unwrap() error {
+	return e.err
+}
+
+func (e invitationError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// AcceptInvitation accepts an Invitation on behalf of the invited User.
+//
+// Use errors.Is with ErrInvitationNotPending or ErrInvitationExpired to tell
+// why an Invitation could not be accepted.
+func (c *Client) AcceptInvitation(ctx context.Context, opts AcceptInvitationOpts) (Invitation, error) {
+	if opts.Invitation == "" {
+		return Invitation{}, errors.New("incomplete arguments: missing Invitation")
+	}
+
+	endpoint := fmt.Sprintf("%s/user_management/invitations/%s/accept", c.endpoint(), opts.Invitation)
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
 	if err != nil {
 		return Invitation{}, err
 	}
-	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return Invitation{}, err
 	}
 	defer res.Body.Close()
 
-	if err = workos_errors.TryGetHTTPError(res); err != nil {
+	if err = c.tryGetHTTPError(res); err != nil {
+		var httpError workos_errors.HTTPError
+		if errors.As(err, &httpError) {
+			if sentinel, ok := invitationErrors[httpError.ErrorCode]; ok {
+				return Invitation{}, invitationError{err: err, sentinel: sentinel}
+			}
+		}
 		return Invitation{}, err
 	}
 
@@ -2,18 +2,47 @@ package usermanagement
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/workos/workos-go/v3/pkg/common"
 	"github.com/workos/workos-go/v3/pkg/mfa"
+	"github.com/workos/workos-go/v3/pkg/retry"
+	"github.com/workos/workos-go/v3/pkg/workos_errors"
 )
 
+func TestInvitationStateUnmarshal(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected InvitationState
+	}{
+		{raw: `"pending"`, expected: Pending},
+		{raw: `"accepted"`, expected: Accepted},
+		{raw: `"expired"`, expected: Expired},
+		{raw: `"revoked"`, expected: Revoked},
+		{raw: `"some_future_state"`, expected: InvitationState("some_future_state")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			var invitation Invitation
+			err := json.Unmarshal([]byte(`{"id":"invitation_123","state":`+test.raw+`}`), &invitation)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, invitation.State)
+		})
+	}
+}
+
 func TestGetUser(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -126,6 +155,97 @@ func getUserTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestGetUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getUsersTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	users, errs := client.GetUsers(context.Background(), []string{"user_123", "user_456", "user_789"})
+
+	require.Len(t, users, 2)
+	require.Equal(t, "user_123", users["user_123"].ID)
+	require.Equal(t, "user_456", users["user_456"].ID)
+	require.NotContains(t, users, "user_789")
+
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "user_789")
+
+	var httpErr workos_errors.HTTPError
+	require.True(t, errors.As(errs[0], &httpErr))
+	require.Equal(t, http.StatusNotFound, httpErr.Code)
+}
+
+func TestGetUsersCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		if requests == getUsersConcurrency {
+			cancel()
+		}
+		mu.Unlock()
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	ids := make([]string, 3*getUsersConcurrency)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("user_%d", i)
+	}
+
+	users, errs := client.GetUsers(ctx, ids)
+	require.Empty(t, users)
+	require.Len(t, errs, len(ids))
+	for _, err := range errs {
+		require.True(t, errors.Is(err, context.Canceled), err.Error())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, getUsersConcurrency, requests)
+}
+
+func getUsersTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/user_management/users/")
+	if id == "user_789" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	body, err := json.Marshal(User{
+		ID:    id,
+		Email: id + "@foo-corp.com",
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
 func TestListUsers(t *testing.T) {
 	t.Run("ListUsers succeeds to fetch Users", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(listUsersTestHandler))
@@ -272,6 +392,129 @@ func listUsersTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestIncrementalUserSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(incrementalUserSyncTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	since := time.Date(2021, 6, 25, 0, 0, 0, 0, time.UTC)
+
+	users, err := client.IncrementalUserSync(context.Background(), since)
+	require.NoError(t, err)
+	require.Equal(t, []User{
+		{ID: "user_2", UpdatedAt: "2021-06-25T19:07:33.155Z"},
+		{ID: "user_4", UpdatedAt: "2021-07-01T08:00:00.000Z"},
+	}, users)
+}
+
+func incrementalUserSyncTestHandler(w http.ResponseWriter, r *http.Request) {
+	res := ListUsersResponse{
+		Data: []User{
+			{ID: "user_1", UpdatedAt: "2021-06-24T19:07:33.155Z"},
+			{ID: "user_2", UpdatedAt: "2021-06-25T19:07:33.155Z"},
+		},
+		ListMetadata: common.ListMetadata{After: "user_2"},
+	}
+	if r.URL.Query().Get("after") == "user_2" {
+		res = ListUsersResponse{
+			Data: []User{
+				{ID: "user_3", UpdatedAt: "2021-06-01T00:00:00.000Z"},
+				{ID: "user_4", UpdatedAt: "2021-07-01T08:00:00.000Z"},
+			},
+		}
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestIncrementalUserSyncOpaqueCursor(t *testing.T) {
+	// Cursors are opaque and may contain characters that must be escaped in
+	// the query, which the server must receive unchanged.
+	const cursor = "dXNlcl8y+/= &after=user_9?#%2F"
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		after := r.URL.Query().Get("after")
+		received = append(received, after)
+
+		res := ListUsersResponse{
+			Data:         []User{{ID: "user_1", UpdatedAt: "2021-06-25T19:07:33.155Z"}},
+			ListMetadata: common.ListMetadata{After: cursor},
+		}
+		if after == cursor {
+			res = ListUsersResponse{
+				Data: []User{{ID: "user_2", UpdatedAt: "2021-06-25T19:07:33.155Z"}},
+			}
+		}
+
+		body, err := json.Marshal(res)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	users, err := client.IncrementalUserSync(context.Background(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Equal(t, []string{"", cursor}, received)
+}
+
+func BenchmarkListUsersLargePage(b *testing.B) {
+	page := ListUsersResponse{Data: make([]User, 1000)}
+	for i := range page.Data {
+		page.Data[i] = User{
+			ID:        fmt.Sprintf("user_%d", i),
+			Email:     fmt.Sprintf("user_%d@foo-corp.com", i),
+			FirstName: "Marcelina",
+			LastName:  "Davis",
+			CreatedAt: "2021-06-25T19:07:33.155Z",
+			UpdatedAt: "2021-06-25T19:07:33.155Z",
+		}
+	}
+	body, err := json.Marshal(page)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ListUsers(context.Background(), ListUsersOpts{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestCreateUser(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -358,6 +601,281 @@ func createUserTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestCreateUserOnRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(createUserTestHandler))
+	defer server.Close()
+
+	var method, path string
+	var payload map[string]interface{}
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.OnRequest = func(r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		require.Equal(t, "Bearer test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}
+
+	user, err := client.CreateUser(context.Background(), CreateUserOpts{
+		Email:     "marcelina@foo-corp.com",
+		FirstName: "Marcelina",
+		Password:  "pass",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "user_01E3JC5F5Z1YJNPGVYWV9SX6GH", user.ID)
+
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/user_management/users", path)
+	require.Equal(t, map[string]interface{}{
+		"email":      "marcelina@foo-corp.com",
+		"first_name": "Marcelina",
+		"password":   "pass",
+	}, payload)
+}
+
+type traceIDKey struct{}
+
+// traceTransport records the trace ID carried by the context of each request.
+type traceTransport struct {
+	traceIDs []interface{}
+	next     http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.traceIDs = append(t.traceIDs, r.Context().Value(traceIDKey{}))
+	return t.next.RoundTrip(r)
+}
+
+func TestRequestsCarryContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getUserTestHandler))
+	defer server.Close()
+
+	transport := &traceTransport{next: server.Client().Transport}
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = &http.Client{Transport: transport}
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "trace_123")
+	_, err := client.GetUser(ctx, GetUserOpts{
+		User: "user_123",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"trace_123"}, transport.traceIDs)
+}
+
+func TestAPIVersion(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		getUserTestHandler(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	_, err := client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Empty(t, header.Get("WorkOS-Version"))
+
+	client.APIVersion = "2024-01-01"
+
+	_, err = client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Equal(t, "2024-01-01", header.Get("WorkOS-Version"))
+}
+
+func TestErrorHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getUserTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	_, err := client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+
+	var handled int
+	client.ErrorHandler = func(res *http.Response, err error) error {
+		handled++
+		return fmt.Errorf("GET %s: %w", res.Request.URL.Path, err)
+	}
+
+	_, err = client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Zero(t, handled)
+
+	client.APIKey = "invalid"
+
+	_, err = client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.Equal(t, 1, handled)
+	require.Contains(t, err.Error(), "GET /user_management/users/user_123: ")
+
+	var httpErr workos_errors.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusUnauthorized, httpErr.Code)
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	body, err := json.Marshal(User{
+		ID:        "user_123",
+		Email:     "marcelina@foo-corp.com",
+		FirstName: strings.Repeat("a", 1024),
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	client.MaxResponseBytes = 512
+	_, err = client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.True(t, errors.Is(err, workos_errors.ErrResponseTooLarge))
+
+	client.MaxResponseBytes = int64(len(body))
+	user, err := client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Equal(t, "user_123", user.ID)
+}
+
+func TestOnRequestComplete(t *testing.T) {
+	type metric struct {
+		method  string
+		path    string
+		attempt int
+		status  int
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(getUserTestHandler))
+	defer server.Close()
+
+	var metrics []metric
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.OnRequestComplete = func(method, path string, attempt int, status int, duration time.Duration) {
+		require.True(t, duration > 0)
+		metrics = append(metrics, metric{method: method, path: path, attempt: attempt, status: status})
+	}
+
+	_, err := client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+
+	client.APIKey = "invalid"
+	_, err = client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.Error(t, err)
+
+	require.Equal(t, []metric{
+		{method: http.MethodGet, path: "/user_management/users/user_123", attempt: 1, status: http.StatusOK},
+		{method: http.MethodGet, path: "/user_management/users/user_123", attempt: 1, status: http.StatusUnauthorized},
+	}, metrics)
+}
+
+func TestOnRequestCompleteWithRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		getUserTestHandler(w, r)
+	}))
+	defer server.Close()
+
+	var attempts []int
+	var statuses []int
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = &http.Client{Transport: &retry.Transport{Backoff: time.Millisecond}}
+	client.OnRequestComplete = func(method, path string, attempt int, status int, duration time.Duration) {
+		attempts = append(attempts, attempt)
+		statuses = append(statuses, status)
+	}
+
+	_, err := client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+
+	require.Equal(t, []int{1, 2, 3}, attempts)
+	require.Equal(t, []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}, statuses)
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		getUserTestHandler(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.DefaultHeaders = map[string]string{
+		"Prefer":        "code=200",
+		"authorization": "Bearer forged",
+		"User-Agent":    "forged",
+	}
+
+	_, err := client.GetUser(context.Background(), GetUserOpts{User: "user_123"})
+	require.NoError(t, err)
+
+	require.Equal(t, "code=200", header.Get("Prefer"))
+	require.Equal(t, "Bearer test", header.Get("Authorization"))
+	require.Contains(t, header.Get("User-Agent"), "workos-go/")
+}
+
+func TestCreateMethodsAccept201(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch r.URL.Path {
+		case "/user_management/users":
+			body = User{ID: "user_123"}
+			w.Header().Set("Location", "/user_management/users/user_123")
+		case "/user_management/organization_memberships":
+			body = OrganizationMembership{ID: "om_123"}
+			w.Header().Set("Location", "/user_management/organization_memberships/om_123")
+		case "/user_management/invitations":
+			body = Invitation{ID: "invitation_123"}
+			w.Header().Set("Location", "/user_management/invitations/invitation_123")
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	user, err := client.CreateUser(context.Background(), CreateUserOpts{Email: "marcelina@foo-corp.com"})
+	require.NoError(t, err)
+	require.Equal(t, "user_123", user.ID)
+
+	membership, err := client.CreateOrganizationMembership(context.Background(), CreateOrganizationMembershipOpts{
+		UserID:         "user_123",
+		OrganizationID: "org_123",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "om_123", membership.ID)
+
+	invitation, err := client.SendInvitation(context.Background(), SendInvitationOpts{Email: "marcelina@foo-corp.com"})
+	require.NoError(t, err)
+	require.Equal(t, "invitation_123", invitation.ID)
+}
+
 func TestUpdateUser(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -375,10 +893,9 @@ func TestUpdateUser(t *testing.T) {
 			scenario: "Request returns User",
 			client:   NewClient("test"),
 			options: UpdateUserOpts{
-				User:          "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
-				FirstName:     "Marcelina",
-				LastName:      "Davis",
-				EmailVerified: false,
+				User:      "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
+				FirstName: "Marcelina",
+				LastName:  "Davis",
 			},
 			expected: User{
 				ID:            "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
@@ -443,6 +960,77 @@ func updateUserTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestUpdateUserOmitsUnsetFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(updateUserTestHandler))
+	defer server.Close()
+
+	var payload map[string]interface{}
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.OnRequest = func(r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}
+
+	_, err := client.UpdateUser(context.Background(), UpdateUserOpts{
+		User:     "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
+		LastName: "Davis",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]interface{}{"last_name": "Davis"}, payload)
+	require.NotContains(t, payload, "first_name")
+}
+
+func TestUpdateUserEmailVerified(t *testing.T) {
+	verified := false
+
+	tests := []struct {
+		scenario      string
+		emailVerified *bool
+		expected      map[string]interface{}
+	}{
+		{
+			scenario: "email_verified is omitted when nil",
+			expected: map[string]interface{}{"first_name": "Marcelina"},
+		},
+		{
+			scenario:      "email_verified is sent when set",
+			emailVerified: &verified,
+			expected: map[string]interface{}{
+				"first_name":     "Marcelina",
+				"email_verified": false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(updateUserTestHandler))
+			defer server.Close()
+
+			var payload map[string]interface{}
+
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+			client.OnRequest = func(r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			}
+
+			_, err := client.UpdateUser(context.Background(), UpdateUserOpts{
+				User:          "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
+				FirstName:     "Marcelina",
+				EmailVerified: test.emailVerified,
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, payload)
+		})
+	}
+}
+
 func TestDeleteUser(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -509,6 +1097,56 @@ func deleteUserTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestDeleteUserWithMemberships(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test" {
+			http.Error(w, "bad auth", http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			listOrganizationMembershipsByUserTestHandler(w, r)
+			return
+		}
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/user_management/organization_memberships/om_1",
+			"/user_management/users/user_123":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	deleted, err := client.DeleteUserWithMemberships(context.Background(), DeleteUserOpts{User: "user_123"})
+	require.NoError(t, err)
+	require.Equal(t, []OrganizationMembership{
+		{ID: "om_1", UserID: "user_123", OrganizationID: "org_1"},
+	}, deleted)
+	require.Equal(t, []string{
+		"DELETE /user_management/organization_memberships/om_1",
+		"DELETE /user_management/organization_memberships/om_2",
+		"DELETE /user_management/users/user_123",
+	}, requests)
+
+	requests = nil
+	deleted, err = client.DeleteUserWithMemberships(context.Background(), DeleteUserOpts{User: "user_456"})
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	require.Equal(t, []string{
+		"DELETE /user_management/organization_memberships/om_3",
+		"DELETE /user_management/users/user_456",
+	}, requests)
+}
+
 func TestClientAuthorizeURL(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -600,66 +1238,205 @@ func TestClientAuthorizeURL(t *testing.T) {
 	}
 }
 
-func TestClientAuthorizeURLInvalidOpts(t *testing.T) {
+func TestClientAuthorizeURLResponseType(t *testing.T) {
 	tests := []struct {
-		scenario string
-		options  GetAuthorizationURLOpts
+		scenario         string
+		additionalParams map[string]string
+		expected         url.Values
 	}{
 		{
-			scenario: "without selector",
-			options: GetAuthorizationURLOpts{
-				ClientID:    "client_123",
-				RedirectURI: "https://example.com/sso/workos/callback",
-			},
+			scenario: "defaults to code",
+			expected: url.Values{"response_type": {ResponseTypeCode}},
 		},
 		{
-			scenario: "without ClientID",
-			options: GetAuthorizationURLOpts{
-				ConnectionID: "connection_123",
-				RedirectURI:  "https://example.com/sso/workos/callback",
-			},
+			scenario:         "can be overridden",
+			additionalParams: map[string]string{"response_type": "token"},
+			expected:         url.Values{"response_type": {"token"}},
 		},
 		{
-			scenario: "without RedirectURI",
-			options: GetAuthorizationURLOpts{
-				ClientID:     "client_123",
-				ConnectionID: "connection_123",
-			},
+			scenario:         "cannot be blanked",
+			additionalParams: map[string]string{"response_type": ""},
+			expected:         url.Values{"response_type": {ResponseTypeCode}},
+		},
+		{
+			scenario:         "does not override other options",
+			additionalParams: map[string]string{"client_id": "client_456", "screen_hint": "sign-up"},
+			expected:         url.Values{"response_type": {ResponseTypeCode}, "client_id": {"client_123"}, "screen_hint": {"sign-up"}},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
 			client := NewClient("test")
-			u, err := client.GetAuthorizationURL(test.options)
-			require.Error(t, err)
-			require.Nil(t, u)
+			u, err := client.GetAuthorizationURL(GetAuthorizationURLOpts{
+				ClientID:         "client_123",
+				Provider:         "GoogleOAuth",
+				RedirectURI:      "https://example.com/sso/workos/callback",
+				AdditionalParams: test.additionalParams,
+			})
+			require.NoError(t, err)
+
+			query := u.Query()
+			for key := range test.expected {
+				require.Equal(t, test.expected.Get(key), query.Get(key))
+			}
 		})
 	}
 }
 
-func TestAuthenticateUserWithPassword(t *testing.T) {
+func TestGetLogoutURL(t *testing.T) {
 	tests := []struct {
 		scenario string
-		client   *Client
-		options  AuthenticateWithPasswordOpts
-		expected AuthenticateResponse
+		options  GetLogoutURLOpts
+		expected string
 		err      bool
-	}{{
-		scenario: "Request without API Key returns an error",
-		client:   NewClient(""),
-		err:      true,
-	},
+	}{
 		{
-			scenario: "Request returns a User",
-			client:   NewClient("test"),
-			options: AuthenticateWithPasswordOpts{
-				ClientID: "project_123",
-				Email:    "employee@foo-corp.com",
-				Password: "test_123",
+			scenario: "without ReturnTo",
+			options:  GetLogoutURLOpts{SessionID: "session_123"},
+			expected: "https://api.workos.com/user_management/sessions/logout?session_id=session_123",
+		},
+		{
+			scenario: "with ReturnTo",
+			options: GetLogoutURLOpts{
+				SessionID: "session_123",
+				ReturnTo:  "https://example.com/signed-out?from=app&lang=en",
 			},
-			expected: AuthenticateResponse{
-				User: User{
+			expected: "https://api.workos.com/user_management/sessions/logout?return_to=https%3A%2F%2Fexample.com%2Fsigned-out%3Ffrom%3Dapp%26lang%3Den&session_id=session_123",
+		},
+		{
+			scenario: "with relative ReturnTo",
+			options: GetLogoutURLOpts{
+				SessionID: "session_123",
+				ReturnTo:  "/signed-out",
+			},
+			err: true,
+		},
+		{
+			scenario: "without SessionID",
+			options:  GetLogoutURLOpts{ReturnTo: "https://example.com"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			client := NewClient("test")
+			u, err := client.GetLogoutURL(test.options)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, u.String())
+
+			returnTo := u.Query().Get("return_to")
+			require.Equal(t, test.options.ReturnTo, returnTo)
+		})
+	}
+}
+
+func TestClientAuthorizeURLInvalidOpts(t *testing.T) {
+	tests := []struct {
+		scenario string
+		options  GetAuthorizationURLOpts
+	}{
+		{
+			scenario: "without selector",
+			options: GetAuthorizationURLOpts{
+				ClientID:    "client_123",
+				RedirectURI: "https://example.com/sso/workos/callback",
+			},
+		},
+		{
+			scenario: "without ClientID",
+			options: GetAuthorizationURLOpts{
+				ConnectionID: "connection_123",
+				RedirectURI:  "https://example.com/sso/workos/callback",
+			},
+		},
+		{
+			scenario: "without RedirectURI",
+			options: GetAuthorizationURLOpts{
+				ClientID:     "client_123",
+				ConnectionID: "connection_123",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			client := NewClient("test")
+			u, err := client.GetAuthorizationURL(test.options)
+			require.Error(t, err)
+			require.Nil(t, u)
+		})
+	}
+}
+
+func TestClientAuthorizeURLAllowedRedirectURIs(t *testing.T) {
+	tests := []struct {
+		scenario    string
+		redirectURI string
+		err         bool
+	}{
+		{
+			scenario:    "allowed redirect uri",
+			redirectURI: "https://staging.example.com/callback",
+		},
+		{
+			scenario:    "disallowed redirect uri",
+			redirectURI: "https://evil.com/callback",
+			err:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			client := NewClient("test")
+			client.AllowedRedirectURIs = []string{
+				"https://example.com/callback",
+				"https://staging.example.com/callback",
+			}
+
+			u, err := client.GetAuthorizationURL(GetAuthorizationURLOpts{
+				ClientID:     "client_123",
+				ConnectionID: "connection_123",
+				RedirectURI:  test.redirectURI,
+			})
+			if test.err {
+				require.Error(t, err)
+				require.Nil(t, u)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.redirectURI, u.Query().Get("redirect_uri"))
+		})
+	}
+}
+
+func TestAuthenticateUserWithPassword(t *testing.T) {
+	tests := []struct {
+		scenario string
+		client   *Client
+		options  AuthenticateWithPasswordOpts
+		expected AuthenticateResponse
+		err      bool
+	}{{
+		scenario: "Request without API Key returns an error",
+		client:   NewClient(""),
+		err:      true,
+	},
+		{
+			scenario: "Request returns a User",
+			client:   NewClient("test"),
+			options: AuthenticateWithPasswordOpts{
+				ClientID: "project_123",
+				Email:    "employee@foo-corp.com",
+				Password: "test_123",
+			},
+			expected: AuthenticateResponse{
+				User: User{
 					ID:        "testUserID",
 					FirstName: "John",
 					LastName:  "Doe",
@@ -906,6 +1683,24 @@ func TestAuthenticateUserWithOrganizationSelection(t *testing.T) {
 		client:   NewClient(""),
 		err:      true,
 	},
+		{
+			scenario: "Request without PendingAuthenticationToken returns an error",
+			client:   NewClient("test"),
+			options: AuthenticateWithOrganizationSelectionOpts{
+				ClientID:       "project_123",
+				OrganizationID: "org_123",
+			},
+			err: true,
+		},
+		{
+			scenario: "Request without OrganizationID returns an error",
+			client:   NewClient("test"),
+			options: AuthenticateWithOrganizationSelectionOpts{
+				ClientID:                   "project_123",
+				PendingAuthenticationToken: "cTDQJTTkTkkVYxQUlKBIxEsFs",
+			},
+			err: true,
+		},
 		{
 			scenario: "Request returns a User",
 			client:   NewClient("test"),
@@ -945,37 +1740,134 @@ func TestAuthenticateUserWithOrganizationSelection(t *testing.T) {
 	}
 }
 
-func authenticationResponseTestHandler(w http.ResponseWriter, r *http.Request) {
+func TestAuthenticateOrganizationSelectionRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
 
-	payload := make(map[string]interface{})
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-	if secret, exists := payload["client_secret"].(string); exists && secret != "" {
-		response := AuthenticateResponse{
-			User: User{
-				ID:        "testUserID",
-				FirstName: "John",
-				LastName:  "Doe",
-				Email:     "employee@foo-corp.com",
-			},
-			OrganizationID: "org_123",
+		w.Header().Set("Content-Type", "application/json")
+		if payload["grant_type"] == "urn:workos:oauth:grant-type:organization-selection" {
+			json.NewEncoder(w).Encode(AuthenticateResponse{
+				User:           User{ID: "user_123"},
+				OrganizationID: payload["organization_id"].(string),
+			})
+			return
 		}
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
-		return
-	}
 
-	w.WriteHeader(http.StatusUnauthorized)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{
+			"code": "organization_selection_required",
+			"message": "The user must choose an organization to finish their authentication.",
+			"pending_authentication_token": "cTDQJTTkTkkVYxQUlKBIxEsFs",
+			"user": {"id": "user_123", "email": "marcelina@foo-corp.com"},
+			"organizations": [
+				{"id": "org_123", "name": "Foo Corp"},
+				{"id": "org_456", "name": "Bar Corp"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	_, err := client.AuthenticateWithPassword(context.Background(), AuthenticateWithPasswordOpts{
+		ClientID: "project_123",
+		Email:    "marcelina@foo-corp.com",
+		Password: "password",
+	})
+
+	var selectionErr OrganizationSelectionRequiredError
+	require.True(t, errors.As(err, &selectionErr))
+	require.Equal(t, "cTDQJTTkTkkVYxQUlKBIxEsFs", selectionErr.PendingAuthenticationToken)
+	require.Equal(t, User{ID: "user_123", Email: "marcelina@foo-corp.com"}, selectionErr.User)
+	require.Equal(t, []OrganizationSummary{
+		{ID: "org_123", Name: "Foo Corp"},
+		{ID: "org_456", Name: "Bar Corp"},
+	}, selectionErr.Organizations)
+
+	var httpErr workos_errors.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, ErrorCodeOrganizationSelectionRequired, httpErr.ErrorCode)
+
+	res, err := client.AuthenticateWithOrganizationSelection(context.Background(), AuthenticateWithOrganizationSelectionOpts{
+		ClientID:                   "project_123",
+		PendingAuthenticationToken: selectionErr.PendingAuthenticationToken,
+		OrganizationID:             selectionErr.Organizations[1].ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "org_456", res.OrganizationID)
 }
 
-func TestSendVerificationEmail(t *testing.T) {
+func TestAuthenticateResponseAccessTokenClaims(t *testing.T) {
+	accessToken := testAccessToken(t, map[string]interface{}{
+		"sub":         "user_123",
+		"sid":         "session_123",
+		"org_id":      "org_123",
+		"role":        "admin",
+		"permissions": []string{"posts:read", "posts:write"},
+		"exp":         1700000300,
+		"iat":         1700000000,
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(AuthenticateResponse{
+			User:           User{ID: "user_123"},
+			OrganizationID: "org_123",
+			AccessToken:    accessToken,
+			RefreshToken:   "refresh_token",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	response, err := client.AuthenticateWithOrganizationSelection(
+		context.Background(),
+		AuthenticateWithOrganizationSelectionOpts{
+			ClientID:                   "project_123",
+			OrganizationID:             "org_123",
+			PendingAuthenticationToken: "cTDQJTTkTkkVYxQUlKBIxEsFs",
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, accessToken, response.AccessToken)
+	require.Equal(t, "refresh_token", response.RefreshToken)
+
+	claims, err := response.AccessTokenClaims()
+	require.NoError(t, err)
+	require.Equal(t, AccessTokenClaims{
+		Subject:        "user_123",
+		SessionID:      "session_123",
+		OrganizationID: "org_123",
+		Role:           "admin",
+		Permissions:    []string{"posts:read", "posts:write"},
+		ExpiresAt:      1700000300,
+		IssuedAt:       1700000000,
+	}, claims)
+
+	_, err = AuthenticateResponse{}.AccessTokenClaims()
+	require.Error(t, err)
+}
+
+// testAccessToken returns an unsigned JWT carrying the given claims.
+func testAccessToken(t *testing.T, claims map[string]interface{}) string {
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+func TestAuthenticateWithRefreshToken(t *testing.T) {
 	tests := []struct {
 		scenario string
 		client   *Client
-		options  SendVerificationEmailOpts
-		expected UserResponse
+		options  AuthenticateWithRefreshTokenOpts
+		expected AuthenticateResponse
 		err      bool
 	}{
 		{
@@ -984,167 +1876,422 @@ func TestSendVerificationEmail(t *testing.T) {
 			err:      true,
 		},
 		{
-			scenario: "Request returns User",
+			scenario: "Request returns a User",
 			client:   NewClient("test"),
-			options: SendVerificationEmailOpts{
-				User: "user_123",
+			options: AuthenticateWithRefreshTokenOpts{
+				ClientID:     "project_123",
+				RefreshToken: "refresh_token_123",
 			},
-			expected: UserResponse{
+			expected: AuthenticateResponse{
 				User: User{
-					ID:            "user_123",
-					Email:         "marcelina@foo-corp.com",
-					FirstName:     "Marcelina",
-					LastName:      "Davis",
-					EmailVerified: true,
-					CreatedAt:     "2021-06-25T19:07:33.155Z",
-					UpdatedAt:     "2021-06-25T19:07:33.155Z",
+					ID:        "testUserID",
+					FirstName: "John",
+					LastName:  "Doe",
+					Email:     "employee@foo-corp.com",
 				},
+				OrganizationID: "org_123",
 			},
 		},
 	}
-
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(sendVerificationEmailTestHandler))
+			server := httptest.NewServer(http.HandlerFunc(authenticationResponseTestHandler))
 			defer server.Close()
 
 			client := test.client
 			client.Endpoint = server.URL
 			client.HTTPClient = server.Client()
 
-			user, err := client.SendVerificationEmail(context.Background(), test.options)
+			response, err := client.AuthenticateWithRefreshToken(context.Background(), test.options)
 			if test.err {
 				require.Error(t, err)
 				return
 			}
 			require.NoError(t, err)
-			require.Equal(t, test.expected, user)
+			require.Equal(t, test.expected, response)
 		})
 	}
 }
 
-func sendVerificationEmailTestHandler(w http.ResponseWriter, r *http.Request) {
-	auth := r.Header.Get("Authorization")
-	if auth != "Bearer test" {
-		http.Error(w, "bad auth", http.StatusUnauthorized)
-		return
+func TestListUsersResponseByID(t *testing.T) {
+	res := ListUsersResponse{
+		Data: []User{
+			{ID: "user_123", Email: "marcelina@foo-corp.com"},
+			{ID: "user_456", Email: "jon@foo-corp.com"},
+		},
 	}
 
-	var body []byte
-	var err error
-
-	if r.URL.Path == "/user_management/users/user_123/email_verification/send" {
-		body, err = json.Marshal(UserResponse{
-			User: User{
-				ID: "user_123",
-
-				Email:         "marcelina@foo-corp.com",
-				FirstName:     "Marcelina",
-				LastName:      "Davis",
-				EmailVerified: true,
-				CreatedAt:     "2021-06-25T19:07:33.155Z",
-				UpdatedAt:     "2021-06-25T19:07:33.155Z",
-			},
-		})
-	}
+	require.Equal(t, map[string]User{
+		"user_123": res.Data[0],
+		"user_456": res.Data[1],
+	}, res.ByID())
+	require.Empty(t, ListUsersResponse{}.ByID())
+}
 
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+func TestAuthenticateResponseDecodesFullUser(t *testing.T) {
+	rawUser := `{
+		"object": "user",
+		"id": "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
+		"email": "marcelina@foo-corp.com",
+		"first_name": "Marcelina",
+		"last_name": "Davis",
+		"email_verified": true,
+		"profile_picture_url": "https://workoscdn.com/images/v1/123abc",
+		"last_sign_in_at": "2021-06-25T19:07:33.155Z",
+		"created_at": "2021-06-25T19:07:33.155Z",
+		"updated_at": "2021-06-25T19:07:33.155Z"
+	}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/user_management/authenticate" {
+			w.Write([]byte(`{"user":` + rawUser + `,"access_token":"access_token","refresh_token":"refresh_token"}`))
+			return
+		}
+		w.Write([]byte(rawUser))
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	res, err := client.AuthenticateWithPassword(context.Background(), AuthenticateWithPasswordOpts{
+		ClientID: "project_123",
+		Email:    "marcelina@foo-corp.com",
+		Password: "password",
+	})
+	require.NoError(t, err)
+
+	expected := User{
+		ID:                "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
+		Email:             "marcelina@foo-corp.com",
+		FirstName:         "Marcelina",
+		LastName:          "Davis",
+		EmailVerified:     true,
+		ProfilePictureURL: "https://workoscdn.com/images/v1/123abc",
+		LastSignInAt:      "2021-06-25T19:07:33.155Z",
+		CreatedAt:         "2021-06-25T19:07:33.155Z",
+		UpdatedAt:         "2021-06-25T19:07:33.155Z",
 	}
+	require.Equal(t, expected, res.User)
 
-	w.WriteHeader(http.StatusOK)
-	w.Write(body)
+	user, err := client.GetUser(context.Background(), GetUserOpts{User: expected.ID})
+	require.NoError(t, err)
+	require.Equal(t, res.User, user)
 }
 
-func TestVerifyEmail(t *testing.T) {
+func TestAuthenticateResponseImpersonator(t *testing.T) {
 	tests := []struct {
-		scenario string
-		client   *Client
-		options  VerifyEmailOpts
-		expected UserResponse
-		err      bool
+		scenario     string
+		body         string
+		impersonated bool
+		expected     *Impersonator
 	}{
 		{
-			scenario: "Request without API Key returns an error",
-			client:   NewClient(""),
-			err:      true,
+			scenario: "Response without impersonator",
+			body:     `{"user":{"id":"user_123"},"access_token":"access_token"}`,
 		},
 		{
-			scenario: "Request returns User",
-			client:   NewClient("test"),
-			options: VerifyEmailOpts{
-				User: "user_123",
-				Code: "testToken",
+			scenario:     "Response with impersonator",
+			body:         `{"user":{"id":"user_123"},"access_token":"access_token","impersonator":{"email":"admin@foocorp.com","reason":"Investigating support ticket 123"}}`,
+			impersonated: true,
+			expected: &Impersonator{
+				Email:  "admin@foocorp.com",
+				Reason: "Investigating support ticket 123",
 			},
-			expected: UserResponse{
-				User: User{
-					ID:            "user_123",
-					Email:         "marcelina@foo-corp.com",
-					FirstName:     "Marcelina",
-					LastName:      "Davis",
-					EmailVerified: true,
-				},
+		},
+		{
+			scenario:     "Response with impersonator without reason",
+			body:         `{"user":{"id":"user_123"},"access_token":"access_token","impersonator":{"email":"admin@foocorp.com"}}`,
+			impersonated: true,
+			expected: &Impersonator{
+				Email: "admin@foocorp.com",
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(verifyEmailCodeTestHandler))
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(test.body))
+			}))
 			defer server.Close()
 
-			client := test.client
+			client := NewClient("test")
 			client.Endpoint = server.URL
 			client.HTTPClient = server.Client()
 
-			user, err := client.VerifyEmail(context.Background(), test.options)
-			if test.err {
-				require.Error(t, err)
-				return
-			}
+			res, err := client.AuthenticateWithCode(context.Background(), AuthenticateWithCodeOpts{
+				ClientID: "project_123",
+				Code:     "code_123",
+			})
 			require.NoError(t, err)
-			require.Equal(t, test.expected, user)
+			require.Equal(t, test.impersonated, res.IsImpersonated())
+			require.Equal(t, test.expected, res.Impersonator)
 		})
 	}
 }
 
-func verifyEmailCodeTestHandler(w http.ResponseWriter, r *http.Request) {
-	auth := r.Header.Get("Authorization")
-	if auth != "Bearer test" {
-		http.Error(w, "bad auth", http.StatusUnauthorized)
-		return
-	}
+func TestAuthenticateResponseAccessTokenExpiresAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user":{"id":"user_123"},"access_token":"access_token","refresh_token":"refresh_token","expires_in":300}`))
+	}))
+	defer server.Close()
 
-	var body []byte
-	var err error
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
 
-	if r.URL.Path == "/user_management/users/user_123/email_verification/confirm" {
-		body, err = json.Marshal(UserResponse{
-			User: User{
-				ID:            "user_123",
-				Email:         "marcelina@foo-corp.com",
-				FirstName:     "Marcelina",
-				LastName:      "Davis",
-				EmailVerified: true,
-			},
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.Now = func() time.Time { return now }
+
+	res, err := client.AuthenticateWithRefreshToken(context.Background(), AuthenticateWithRefreshTokenOpts{
+		ClientID:     "project_123",
+		RefreshToken: "refresh_token",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 300, res.ExpiresIn)
+	require.Equal(t, now.Add(300*time.Second), res.AccessTokenExpiresAt)
+}
+
+func TestAuthenticateWithCodeVerifier(t *testing.T) {
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"user":{"id":"user_123"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	tests := []struct {
+		scenario string
+		verifier string
+		err      string
+	}{
+		{
+			scenario: "Valid verifier is sent",
+			verifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk~.",
+		},
+		{
+			scenario: "Too short verifier returns an error",
+			verifier: "dBjftJeZ4CVP",
+			err:      "invalid arguments: CodeVerifier must be between 43 and 128 characters, got 12",
+		},
+		{
+			scenario: "Too long verifier returns an error",
+			verifier: strings.Repeat("a", 129),
+			err:      "invalid arguments: CodeVerifier must be between 43 and 128 characters, got 129",
+		},
+		{
+			scenario: "Verifier with invalid characters returns an error",
+			verifier: "dBjftJeZ4CVP+mB92K27uhbUJU1p1r/wW1gFWFOEjXk=",
+			err:      `invalid arguments: CodeVerifier contains invalid character '+'`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			sent = nil
+
+			_, err := client.AuthenticateWithCode(context.Background(), AuthenticateWithCodeOpts{
+				ClientID:     "project_123",
+				Code:         "code_123",
+				CodeVerifier: test.verifier,
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Nil(t, sent)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.verifier, sent["code_verifier"])
 		})
 	}
+}
 
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+func TestAuthenticateWithCodeProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"GitHubOAuth is not configured for this environment.","provider":"GitHubOAuth"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	_, err := client.AuthenticateWithCode(context.Background(), AuthenticateWithCodeOpts{
+		ClientID: "project_123",
+		Code:     "code_123",
+	})
+
+	var providerErr ProviderError
+	require.True(t, errors.As(err, &providerErr))
+	require.Equal(t, "GitHubOAuth", providerErr.Provider)
+	require.Equal(t, "GitHubOAuth is not configured for this environment.", providerErr.Reason)
+	require.True(t, workos_errors.IsBadRequest(err))
+}
+
+func TestAuthenticateRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"authentication_rate_limit","message":"Too many failed attempts.","retry_after":120}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	_, err := client.AuthenticateWithPassword(context.Background(), AuthenticateWithPasswordOpts{
+		ClientID: "project_123",
+		Email:    "employee@foo-corp.com",
+		Password: "wrong",
+	})
+	require.Error(t, err)
+
+	retryAfter, ok := IsAuthenticationRateLimited(err)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, retryAfter)
+
+	_, ok = IsAuthenticationRateLimited(workos_errors.HTTPError{Code: http.StatusBadRequest})
+	require.False(t, ok)
+}
+
+func TestAuthenticateClientSecret(t *testing.T) {
+	authenticate := map[string]func(*Client) (AuthenticateResponse, error){
+		"AuthenticateWithPassword": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithPassword(context.Background(), AuthenticateWithPasswordOpts{})
+		},
+		"AuthenticateWithCode": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithCode(context.Background(), AuthenticateWithCodeOpts{})
+		},
+		"AuthenticateWithMagicAuth": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithMagicAuth(context.Background(), AuthenticateWithMagicAuthOpts{})
+		},
+		"AuthenticateWithTOTP": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithTOTP(context.Background(), AuthenticateWithTOTPOpts{})
+		},
+		"AuthenticateWithEmailVerificationCode": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithEmailVerificationCode(context.Background(), AuthenticateWithEmailVerificationCodeOpts{})
+		},
+		"AuthenticateWithOrganizationSelection": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithOrganizationSelection(context.Background(), AuthenticateWithOrganizationSelectionOpts{
+				PendingAuthenticationToken: "cTDQJTTkTkkVYxQUlKBIxEsFs",
+				OrganizationID:             "org_123",
+			})
+		},
+		"AuthenticateWithRefreshToken": func(c *Client) (AuthenticateResponse, error) {
+			return c.AuthenticateWithRefreshToken(context.Background(), AuthenticateWithRefreshTokenOpts{})
+		},
+	}
+
+	for name, fn := range authenticate {
+		t.Run(name, func(t *testing.T) {
+			var secret interface{}
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				var payload map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&payload)
+				secret = payload["client_secret"]
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := NewClient("")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			_, err := fn(client)
+			require.Equal(t, ErrMissingClientSecret, err)
+			require.Equal(t, 0, requests)
+
+			client.APIKey = "test"
+
+			_, err = fn(client)
+			require.NoError(t, err)
+			require.Equal(t, "test", secret)
+		})
+	}
+}
+
+func TestAuthenticateWithMagicAuthCodeValidity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		w.Header().Set("Content-Type", "application/json")
+		if payload["code"] != "123456" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"invalid_one_time_code","message":"Magic Auth code has expired."}`))
+			return
+		}
+		w.Write([]byte(`{"user":{"id":"user_123","email":"marcelina@foo-corp.com"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	res, err := client.AuthenticateWithMagicAuth(context.Background(), AuthenticateWithMagicAuthOpts{
+		ClientID: "project_123",
+		Code:     "123456",
+		Email:    "marcelina@foo-corp.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "user_123", res.User.ID)
+
+	_, err = client.AuthenticateWithMagicAuth(context.Background(), AuthenticateWithMagicAuthOpts{
+		ClientID: "project_123",
+		Code:     "654321",
+		Email:    "marcelina@foo-corp.com",
+	})
+	require.True(t, workos_errors.IsBadRequest(err))
+	require.Equal(t, "invalid_one_time_code", err.(workos_errors.HTTPError).ErrorCode)
+}
+
+func authenticationResponseTestHandler(w http.ResponseWriter, r *http.Request) {
+
+	payload := make(map[string]interface{})
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if secret, exists := payload["client_secret"].(string); exists && secret != "" {
+		response := AuthenticateResponse{
+			User: User{
+				ID:        "testUserID",
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "employee@foo-corp.com",
+			},
+			OrganizationID: "org_123",
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write(body)
+	w.WriteHeader(http.StatusUnauthorized)
 }
 
-func TestSendPasswordResetEmail(t *testing.T) {
+func TestSendVerificationEmail(t *testing.T) {
 	tests := []struct {
 		scenario string
 		client   *Client
-		options  SendPasswordResetEmailOpts
+		options  SendVerificationEmailOpts
+		expected UserResponse
 		err      bool
 	}{
 		{
@@ -1153,49 +2300,84 @@ func TestSendPasswordResetEmail(t *testing.T) {
 			err:      true,
 		},
 		{
-			scenario: "Successful request",
+			scenario: "Request returns User",
 			client:   NewClient("test"),
-			options: SendPasswordResetEmailOpts{
-				Email:            "marcelina@foo-corp.com",
-				PasswordResetUrl: "https://foo-corp.com/reset-password",
+			options: SendVerificationEmailOpts{
+				User: "user_123",
+			},
+			expected: UserResponse{
+				User: User{
+					ID:            "user_123",
+					Email:         "marcelina@foo-corp.com",
+					FirstName:     "Marcelina",
+					LastName:      "Davis",
+					EmailVerified: true,
+					CreatedAt:     "2021-06-25T19:07:33.155Z",
+					UpdatedAt:     "2021-06-25T19:07:33.155Z",
+				},
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(sendPasswordResetEmailTestHandler))
+			server := httptest.NewServer(http.HandlerFunc(sendVerificationEmailTestHandler))
 			defer server.Close()
 
 			client := test.client
 			client.Endpoint = server.URL
 			client.HTTPClient = server.Client()
 
-			err := client.SendPasswordResetEmail(context.Background(), test.options)
+			user, err := client.SendVerificationEmail(context.Background(), test.options)
 			if test.err {
 				require.Error(t, err)
 				return
 			}
 			require.NoError(t, err)
+			require.Equal(t, test.expected, user)
 		})
 	}
 }
 
-func sendPasswordResetEmailTestHandler(w http.ResponseWriter, r *http.Request) {
+func sendVerificationEmailTestHandler(w http.ResponseWriter, r *http.Request) {
 	auth := r.Header.Get("Authorization")
 	if auth != "Bearer test" {
 		http.Error(w, "bad auth", http.StatusUnauthorized)
 		return
 	}
 
+	var body []byte
+	var err error
+
+	if r.URL.Path == "/user_management/users/user_123/email_verification/send" {
+		body, err = json.Marshal(UserResponse{
+			User: User{
+				ID: "user_123",
+
+				Email:         "marcelina@foo-corp.com",
+				FirstName:     "Marcelina",
+				LastName:      "Davis",
+				EmailVerified: true,
+				CreatedAt:     "2021-06-25T19:07:33.155Z",
+				UpdatedAt:     "2021-06-25T19:07:33.155Z",
+			},
+		})
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
+	w.Write(body)
 }
 
-func TestResetPassword(t *testing.T) {
+func TestVerifyEmail(t *testing.T) {
 	tests := []struct {
 		scenario string
 		client   *Client
-		options  ResetPasswordOpts
+		options  VerifyEmailOpts
 		expected UserResponse
 		err      bool
 	}{
@@ -1207,13 +2389,13 @@ func TestResetPassword(t *testing.T) {
 		{
 			scenario: "Request returns User",
 			client:   NewClient("test"),
-			options: ResetPasswordOpts{
-				Token: "testToken",
+			options: VerifyEmailOpts{
+				User: "user_123",
+				Code: "testToken",
 			},
 			expected: UserResponse{
 				User: User{
-					ID: "user_123",
-
+					ID:            "user_123",
 					Email:         "marcelina@foo-corp.com",
 					FirstName:     "Marcelina",
 					LastName:      "Davis",
@@ -1225,14 +2407,14 @@ func TestResetPassword(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(resetPasswordHandler))
+			server := httptest.NewServer(http.HandlerFunc(verifyEmailCodeTestHandler))
 			defer server.Close()
 
 			client := test.client
 			client.Endpoint = server.URL
 			client.HTTPClient = server.Client()
 
-			user, err := client.ResetPassword(context.Background(), test.options)
+			user, err := client.VerifyEmail(context.Background(), test.options)
 			if test.err {
 				require.Error(t, err)
 				return
@@ -1243,7 +2425,7 @@ func TestResetPassword(t *testing.T) {
 	}
 }
 
-func resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+func verifyEmailCodeTestHandler(w http.ResponseWriter, r *http.Request) {
 	auth := r.Header.Get("Authorization")
 	if auth != "Bearer test" {
 		http.Error(w, "bad auth", http.StatusUnauthorized)
@@ -1253,11 +2435,10 @@ func resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	var body []byte
 	var err error
 
-	if r.URL.Path == "/user_management/password_reset/confirm" {
+	if r.URL.Path == "/user_management/users/user_123/email_verification/confirm" {
 		body, err = json.Marshal(UserResponse{
 			User: User{
-				ID: "user_123",
-
+				ID:            "user_123",
 				Email:         "marcelina@foo-corp.com",
 				FirstName:     "Marcelina",
 				LastName:      "Davis",
@@ -1275,11 +2456,11 @@ func resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
-func TestSendMagicAuthCode(t *testing.T) {
+func TestSendPasswordResetEmail(t *testing.T) {
 	tests := []struct {
 		scenario string
 		client   *Client
-		options  SendMagicAuthCodeOpts
+		options  SendPasswordResetEmailOpts
 		err      bool
 	}{
 		{
@@ -1290,22 +2471,23 @@ func TestSendMagicAuthCode(t *testing.T) {
 		{
 			scenario: "Successful request",
 			client:   NewClient("test"),
-			options: SendMagicAuthCodeOpts{
-				Email: "marcelina@foo-corp.com",
+			options: SendPasswordResetEmailOpts{
+				Email:            "marcelina@foo-corp.com",
+				PasswordResetUrl: "https://foo-corp.com/reset-password",
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(sendMagicAuthCodeTestHandler))
+			server := httptest.NewServer(http.HandlerFunc(sendPasswordResetEmailTestHandler))
 			defer server.Close()
 
 			client := test.client
 			client.Endpoint = server.URL
 			client.HTTPClient = server.Client()
 
-			err := client.SendMagicAuthCode(context.Background(), test.options)
+			err := client.SendPasswordResetEmail(context.Background(), test.options)
 			if test.err {
 				require.Error(t, err)
 				return
@@ -1315,48 +2497,56 @@ func TestSendMagicAuthCode(t *testing.T) {
 	}
 }
 
-func sendMagicAuthCodeTestHandler(w http.ResponseWriter, r *http.Request) {
+func sendPasswordResetEmailTestHandler(w http.ResponseWriter, r *http.Request) {
 	auth := r.Header.Get("Authorization")
 	if auth != "Bearer test" {
 		http.Error(w, "bad auth", http.StatusUnauthorized)
 		return
 	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
-func TestEnrollAuthFactor(t *testing.T) {
+func TestResetPassword(t *testing.T) {
 	tests := []struct {
 		scenario string
 		client   *Client
-		options  EnrollAuthFactorOpts
-		expected EnrollAuthFactorResponse
+		options  ResetPasswordOpts
+		expected UserResponse
 		err      bool
 	}{
 		{
 			scenario: "Request without API Key returns an error",
 			client:   NewClient(""),
-			err:      true,
+			options: ResetPasswordOpts{
+				Token:       "testToken",
+				NewPassword: "new_password",
+			},
+			err: true,
+		},
+		{
+			scenario: "Request without NewPassword returns an error",
+			client:   NewClient("test"),
+			options: ResetPasswordOpts{
+				Token: "testToken",
+			},
+			err: true,
 		},
 		{
 			scenario: "Request returns User",
 			client:   NewClient("test"),
-			options: EnrollAuthFactorOpts{
-				User: "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
-				Type: mfa.TOTP,
+			options: ResetPasswordOpts{
+				Token:       "testToken",
+				NewPassword: "new_password",
 			},
-			expected: EnrollAuthFactorResponse{
-				Factor: mfa.Factor{
-					ID:        "auth_factor_test123",
-					CreatedAt: "2022-02-17T22:39:26.616Z",
-					UpdatedAt: "2022-02-17T22:39:26.616Z",
-					Type:      "generic_otp",
-				},
-				Challenge: mfa.Challenge{
-					ID:        "auth_challenge_test123",
-					CreatedAt: "2022-02-17T22:39:26.616Z",
-					UpdatedAt: "2022-02-17T22:39:26.616Z",
-					FactorID:  "auth_factor_test123",
-					ExpiresAt: "2022-02-17T22:39:26.616Z",
+			expected: UserResponse{
+				User: User{
+					ID: "user_123",
+
+					Email:         "marcelina@foo-corp.com",
+					FirstName:     "Marcelina",
+					LastName:      "Davis",
+					EmailVerified: true,
 				},
 			},
 		},
@@ -1364,14 +2554,14 @@ func TestEnrollAuthFactor(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(enrollAuthFactorTestHandler))
+			server := httptest.NewServer(http.HandlerFunc(resetPasswordHandler))
 			defer server.Close()
 
 			client := test.client
 			client.Endpoint = server.URL
 			client.HTTPClient = server.Client()
 
-			user, err := client.EnrollAuthFactor(context.Background(), test.options)
+			user, err := client.ResetPassword(context.Background(), test.options)
 			if test.err {
 				require.Error(t, err)
 				return
@@ -1382,7 +2572,30 @@ func TestEnrollAuthFactor(t *testing.T) {
 	}
 }
 
-func enrollAuthFactorTestHandler(w http.ResponseWriter, r *http.Request) {
+func TestResetPasswordSendsTokenAndNewPassword(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		resetPasswordHandler(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	_, err := client.ResetPassword(context.Background(), ResetPasswordOpts{
+		Token:       "testToken",
+		NewPassword: "new_password",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"token":        "testToken",
+		"new_password": "new_password",
+	}, payload)
+}
+
+func resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	auth := r.Header.Get("Authorization")
 	if auth != "Bearer test" {
 		http.Error(w, "bad auth", http.StatusUnauthorized)
@@ -1392,15 +2605,264 @@ func enrollAuthFactorTestHandler(w http.ResponseWriter, r *http.Request) {
 	var body []byte
 	var err error
 
-	if r.URL.Path == "/user_management/users/user_01E3JC5F5Z1YJNPGVYWV9SX6GH/auth_factors" {
-		body, err = json.Marshal(EnrollAuthFactorResponse{
-			Factor: mfa.Factor{
-				ID:        "auth_factor_test123",
-				CreatedAt: "2022-02-17T22:39:26.616Z",
-				UpdatedAt: "2022-02-17T22:39:26.616Z",
-				Type:      "generic_otp",
-			},
-			Challenge: mfa.Challenge{
+	if r.URL.Path == "/user_management/password_reset/confirm" {
+		body, err = json.Marshal(UserResponse{
+			User: User{
+				ID: "user_123",
+
+				Email:         "marcelina@foo-corp.com",
+				FirstName:     "Marcelina",
+				LastName:      "Davis",
+				EmailVerified: true,
+			},
+		})
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestSendMagicAuthCode(t *testing.T) {
+	tests := []struct {
+		scenario string
+		client   *Client
+		options  SendMagicAuthCodeOpts
+		err      bool
+	}{
+		{
+			scenario: "Request without API Key returns an error",
+			client:   NewClient(""),
+			err:      true,
+		},
+		{
+			scenario: "Successful request",
+			client:   NewClient("test"),
+			options: SendMagicAuthCodeOpts{
+				Email: "marcelina@foo-corp.com",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(sendMagicAuthCodeTestHandler))
+			defer server.Close()
+
+			client := test.client
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			err := client.SendMagicAuthCode(context.Background(), test.options)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSendMagicAuthCodeThrottle(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		sendMagicAuthCodeTestHandler(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.MagicAuthThrottle = time.Minute
+
+	err := client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{
+		Email: "marcelina@foo-corp.com",
+	})
+	require.NoError(t, err)
+
+	err = client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{
+		Email: "Marcelina@foo-corp.com",
+	})
+	require.Equal(t, ErrMagicAuthThrottled, err)
+
+	err = client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{
+		Email: "davis@foo-corp.com",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, requests)
+}
+
+func TestSendMagicAuthCodeThrottleConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		// Keep the request in flight so that the other calls race with it.
+		time.Sleep(10 * time.Millisecond)
+		sendMagicAuthCodeTestHandler(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.MagicAuthThrottle = time.Minute
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{
+				Email: "marcelina@foo-corp.com",
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	sent := 0
+	for _, err := range errs {
+		if err == nil {
+			sent++
+			continue
+		}
+		require.Equal(t, ErrMagicAuthThrottled, err)
+	}
+	require.Equal(t, 1, sent)
+	require.Equal(t, 1, requests)
+}
+
+func TestSendMagicAuthCodeThrottleFailureAndPruning(t *testing.T) {
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		sendMagicAuthCodeTestHandler(w, r)
+	}))
+	defer server.Close()
+
+	now := time.Date(2021, 6, 25, 19, 7, 33, 0, time.UTC)
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.MagicAuthThrottle = time.Minute
+	client.Now = func() time.Time { return now }
+
+	// A failed send does not throttle the next one.
+	err := client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{Email: "marcelina@foo-corp.com"})
+	require.Error(t, err)
+
+	fail = false
+	err = client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{Email: "marcelina@foo-corp.com"})
+	require.NoError(t, err)
+
+	// Expired records are pruned when a new one is written.
+	now = now.Add(2 * time.Minute)
+	err = client.SendMagicAuthCode(context.Background(), SendMagicAuthCodeOpts{Email: "davis@foo-corp.com"})
+	require.NoError(t, err)
+	require.Len(t, client.magicAuthSentAt, 1)
+	require.Contains(t, client.magicAuthSentAt, "davis@foo-corp.com")
+}
+
+func sendMagicAuthCodeTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestEnrollAuthFactor(t *testing.T) {
+	tests := []struct {
+		scenario string
+		client   *Client
+		options  EnrollAuthFactorOpts
+		expected EnrollAuthFactorResponse
+		err      bool
+	}{
+		{
+			scenario: "Request without API Key returns an error",
+			client:   NewClient(""),
+			err:      true,
+		},
+		{
+			scenario: "Request returns User",
+			client:   NewClient("test"),
+			options: EnrollAuthFactorOpts{
+				User: "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
+				Type: mfa.TOTP,
+			},
+			expected: EnrollAuthFactorResponse{
+				Factor: mfa.Factor{
+					ID:        "auth_factor_test123",
+					CreatedAt: "2022-02-17T22:39:26.616Z",
+					UpdatedAt: "2022-02-17T22:39:26.616Z",
+					Type:      "generic_otp",
+				},
+				Challenge: mfa.Challenge{
+					ID:        "auth_challenge_test123",
+					CreatedAt: "2022-02-17T22:39:26.616Z",
+					UpdatedAt: "2022-02-17T22:39:26.616Z",
+					FactorID:  "auth_factor_test123",
+					ExpiresAt: "2022-02-17T22:39:26.616Z",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(enrollAuthFactorTestHandler))
+			defer server.Close()
+
+			client := test.client
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			user, err := client.EnrollAuthFactor(context.Background(), test.options)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, user)
+		})
+	}
+}
+
+func enrollAuthFactorTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	var body []byte
+	var err error
+
+	if r.URL.Path == "/user_management/users/user_01E3JC5F5Z1YJNPGVYWV9SX6GH/auth_factors" {
+		body, err = json.Marshal(EnrollAuthFactorResponse{
+			Factor: mfa.Factor{
+				ID:        "auth_factor_test123",
+				CreatedAt: "2022-02-17T22:39:26.616Z",
+				UpdatedAt: "2022-02-17T22:39:26.616Z",
+				Type:      "generic_otp",
+			},
+			Challenge: mfa.Challenge{
 				ID:        "auth_challenge_test123",
 				CreatedAt: "2022-02-17T22:39:26.616Z",
 				UpdatedAt: "2022-02-17T22:39:26.616Z",
@@ -1538,6 +3000,42 @@ func TestGetOrganizationMembership(t *testing.T) {
 				ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
 				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
 				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				Role:           RoleResponse{Slug: "admin"},
+				CreatedAt:      "2021-06-25T19:07:33.155Z",
+				UpdatedAt:      "2021-06-25T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Request with role permissions expansion returns the permissions",
+			client:   NewClient("test"),
+			options: GetOrganizationMembershipOpts{
+				OrganizationMembership: "om_01E4ZCR3C56J083X43JQXF3JK5",
+				Expand:                 []Expansion{ExpandRolePermissions},
+			},
+			expected: OrganizationMembership{
+				ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
+				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				Role: RoleResponse{
+					Slug:        "admin",
+					Permissions: []string{"posts:read", "posts:write"},
+				},
+				CreatedAt: "2021-06-25T19:07:33.155Z",
+				UpdatedAt: "2021-06-25T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Request with role permissions expansion keeps a role that is not listed",
+			client:   NewClient("test"),
+			options: GetOrganizationMembershipOpts{
+				OrganizationMembership: "om_unknown_role",
+				Expand:                 []Expansion{ExpandRolePermissions},
+			},
+			expected: OrganizationMembership{
+				ID:             "om_unknown_role",
+				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				Role:           RoleResponse{Slug: "billing"},
 				CreatedAt:      "2021-06-25T19:07:33.155Z",
 				UpdatedAt:      "2021-06-25T19:07:33.155Z",
 			},
@@ -1579,11 +3077,32 @@ func getOrganizationMembershipTestHandler(w http.ResponseWriter, r *http.Request
 			ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
 			UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
 			OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+			Role:           RoleResponse{Slug: "admin"},
+			CreatedAt:      "2021-06-25T19:07:33.155Z",
+			UpdatedAt:      "2021-06-25T19:07:33.155Z",
+		})
+	}
+
+	if r.URL.Path == "/user_management/organization_memberships/om_unknown_role" {
+		body, err = json.Marshal(OrganizationMembership{
+			ID:             "om_unknown_role",
+			UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+			OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+			Role:           RoleResponse{Slug: "billing"},
 			CreatedAt:      "2021-06-25T19:07:33.155Z",
 			UpdatedAt:      "2021-06-25T19:07:33.155Z",
 		})
 	}
 
+	if r.URL.Path == "/organizations/org_01E4ZCR3C56J083X43JQXF3JK5/roles" {
+		body, err = json.Marshal(ListOrganizationRolesResponse{
+			Data: []Role{
+				{Slug: "member", Permissions: []string{"posts:read"}},
+				{Slug: "admin", Permissions: []string{"posts:read", "posts:write"}},
+			},
+		})
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -1593,6 +3112,232 @@ func getOrganizationMembershipTestHandler(w http.ResponseWriter, r *http.Request
 	w.Write(body)
 }
 
+func TestListOrganizationMembersWithRoles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(listOrganizationMembersWithRolesTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	members, err := client.ListOrganizationMembersWithRoles(context.Background(), "org_123")
+	require.NoError(t, err)
+
+	admin := Role{ID: "role_1", Name: "Admin", Slug: "admin", Permissions: []string{"posts:read", "posts:write"}}
+	member := Role{ID: "role_2", Name: "Member", Slug: "member", Permissions: []string{"posts:read"}}
+	require.Equal(t, []MembershipWithRole{
+		{
+			Membership: OrganizationMembership{ID: "om_1", UserID: "user_1", OrganizationID: "org_123", Role: RoleResponse{Slug: "admin"}},
+			Role:       admin,
+		},
+		{
+			Membership: OrganizationMembership{ID: "om_2", UserID: "user_2", OrganizationID: "org_123", Role: RoleResponse{Slug: "member"}},
+			Role:       member,
+		},
+		{
+			Membership: OrganizationMembership{ID: "om_3", UserID: "user_3", OrganizationID: "org_123", Role: RoleResponse{Slug: "member"}},
+			Role:       member,
+		},
+	}, members)
+
+	// A role missing from the Organization's roles falls back to the
+	// membership's own role.
+	members, err = client.ListOrganizationMembersWithRoles(context.Background(), "org_unknown_role")
+	require.NoError(t, err)
+	require.Equal(t, []MembershipWithRole{
+		{
+			Membership: OrganizationMembership{
+				ID:             "om_1",
+				UserID:         "user_1",
+				OrganizationID: "org_unknown_role",
+				Role:           RoleResponse{Slug: "owner", Permissions: []string{"posts:delete"}},
+			},
+			Role: Role{Slug: "owner", Permissions: []string{"posts:delete"}},
+		},
+		{
+			Membership: OrganizationMembership{
+				ID:             "om_2",
+				UserID:         "user_2",
+				OrganizationID: "org_unknown_role",
+				Role:           RoleResponse{Slug: "admin"},
+			},
+			Role: admin,
+		},
+	}, members)
+
+	_, err = client.ListOrganizationMembersWithRoles(context.Background(), "")
+	require.Error(t, err)
+}
+
+func listOrganizationMembersWithRolesTestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	var res interface{}
+	switch {
+	case r.URL.Path == "/organizations/org_123/roles", r.URL.Path == "/organizations/org_unknown_role/roles":
+		res = ListOrganizationRolesResponse{
+			Data: []Role{
+				{ID: "role_1", Name: "Admin", Slug: "admin", Permissions: []string{"posts:read", "posts:write"}},
+				{ID: "role_2", Name: "Member", Slug: "member", Permissions: []string{"posts:read"}},
+			},
+		}
+	case r.URL.Query().Get("organization_id") == "org_unknown_role":
+		res = ListOrganizationMembershipsResponse{
+			Data: []OrganizationMembership{
+				{ID: "om_1", UserID: "user_1", OrganizationID: "org_unknown_role", Role: RoleResponse{Slug: "owner", Permissions: []string{"posts:delete"}}},
+				{ID: "om_2", UserID: "user_2", OrganizationID: "org_unknown_role", Role: RoleResponse{Slug: "admin"}},
+			},
+		}
+	case r.URL.Query().Get("after") == "om_2":
+		res = ListOrganizationMembershipsResponse{
+			Data: []OrganizationMembership{
+				{ID: "om_3", UserID: "user_3", OrganizationID: "org_123", Role: RoleResponse{Slug: "member"}},
+			},
+		}
+	default:
+		res = ListOrganizationMembershipsResponse{
+			Data: []OrganizationMembership{
+				{ID: "om_1", UserID: "user_1", OrganizationID: "org_123", Role: RoleResponse{Slug: "admin"}},
+				{ID: "om_2", UserID: "user_2", OrganizationID: "org_123", Role: RoleResponse{Slug: "member"}},
+			},
+			ListMetadata: common.ListMetadata{After: "om_2"},
+		}
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestUpdateOrganizationMembership(t *testing.T) {
+	tests := []struct {
+		scenario string
+		options  UpdateOrganizationMembershipOpts
+		expected OrganizationMembership
+		err      bool
+	}{
+		{
+			scenario: "Request updates the role",
+			options: UpdateOrganizationMembershipOpts{
+				OrganizationMembership: "om_01E4ZCR3C56J083X43JQXF3JK5",
+				RoleSlug:               "member",
+			},
+			expected: OrganizationMembership{
+				ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
+				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				Role:           RoleResponse{Slug: "member"},
+			},
+		},
+		{
+			scenario: "No-op role update succeeds",
+			options: UpdateOrganizationMembershipOpts{
+				OrganizationMembership: "om_01E4ZCR3C56J083X43JQXF3JK5",
+				RoleSlug:               "admin",
+			},
+			expected: OrganizationMembership{
+				ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
+				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				Role:           RoleResponse{Slug: "admin"},
+				CreatedAt:      "2021-06-25T19:07:33.155Z",
+				UpdatedAt:      "2021-06-25T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Conflicting role update returns an error",
+			options: UpdateOrganizationMembershipOpts{
+				OrganizationMembership: "om_01E4ZCR3C56J083X43JQXF3JK5",
+				RoleSlug:               "owner",
+			},
+			err: true,
+		},
+		{
+			scenario: "Request without role returns an error",
+			options: UpdateOrganizationMembershipOpts{
+				OrganizationMembership: "om_01E4ZCR3C56J083X43JQXF3JK5",
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(updateOrganizationMembershipTestHandler))
+			defer server.Close()
+
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			membership, err := client.UpdateOrganizationMembership(context.Background(), test.options)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, membership)
+		})
+	}
+}
+
+// updateOrganizationMembershipTestHandler answers updates that don't change the
+// role with a 409, as a strict API would.
+func updateOrganizationMembershipTestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		getOrganizationMembershipTestHandler(w, r)
+		return
+	}
+
+	var opts UpdateOrganizationMembershipOpts
+	json.NewDecoder(r.Body).Decode(&opts)
+
+	if opts.RoleSlug != "member" {
+		http.Error(w, "conflict", http.StatusConflict)
+		return
+	}
+
+	body, _ := json.Marshal(OrganizationMembership{
+		ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
+		UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+		OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+		Role:           RoleResponse{Slug: opts.RoleSlug},
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestListOrganizationRoles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getOrganizationMembershipTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	roles, err := client.ListOrganizationRoles(context.Background(), ListOrganizationRolesOpts{
+		OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ListOrganizationRolesResponse{
+		Data: []Role{
+			{Slug: "member", Permissions: []string{"posts:read"}},
+			{Slug: "admin", Permissions: []string{"posts:read", "posts:write"}},
+		},
+	}, roles)
+
+	_, err = client.ListOrganizationRoles(context.Background(), ListOrganizationRolesOpts{})
+	require.Error(t, err)
+}
+
 func TestListOrganizationMemberships(t *testing.T) {
 	t.Run("ListOrganizationMemberships succeeds to fetch OrganizationMemberships belonging to an Organization", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(listOrganizationMembershipsTestHandler))
@@ -1673,36 +3418,435 @@ func listOrganizationMembershipsTestHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var body []byte
-	var err error
+	var body []byte
+	var err error
+
+	if r.URL.Path == "/user_management/organization_memberships" {
+		body, err = json.Marshal(struct {
+			ListOrganizationMembershipsResponse
+		}{
+			ListOrganizationMembershipsResponse: ListOrganizationMembershipsResponse{
+				Data: []OrganizationMembership{
+					{
+						ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
+						UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+						OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+						CreatedAt:      "2021-06-25T19:07:33.155Z",
+						UpdatedAt:      "2021-06-25T19:07:33.155Z",
+					},
+				},
+				ListMetadata: common.ListMetadata{
+					After: "",
+				},
+			},
+		})
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestListOrganizationMembershipsOrder(t *testing.T) {
+	memberships := []OrganizationMembership{
+		{ID: "om_1", UserID: "user_123", OrganizationID: "org_1", CreatedAt: "2021-06-25T19:07:33.155Z"},
+		{ID: "om_2", UserID: "user_123", OrganizationID: "org_2", CreatedAt: "2021-06-26T19:07:33.155Z"},
+		{ID: "om_3", UserID: "user_123", OrganizationID: "org_3", CreatedAt: "2021-06-27T19:07:33.155Z"},
+	}
+
+	var orders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		orders = append(orders, q.Get("order"))
+
+		sorted := make([]OrganizationMembership, len(memberships))
+		copy(sorted, memberships)
+		if q.Get("order") != string(Asc) {
+			for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+				sorted[i], sorted[j] = sorted[j], sorted[i]
+			}
+		}
+
+		// Pages of two records.
+		start := 0
+		for i, m := range sorted {
+			if m.ID == q.Get("after") {
+				start = i + 1
+			}
+		}
+		end := start + 2
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+
+		res := ListOrganizationMembershipsResponse{Data: sorted[start:end]}
+		if end < len(sorted) {
+			res.ListMetadata.After = sorted[end-1].ID
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	res, err := client.ListOrganizationMemberships(context.Background(), ListOrganizationMembershipsOpts{
+		UserID: "user_123",
+		Order:  Asc,
+	})
+	require.NoError(t, err)
+	require.Equal(t, memberships[:2], res.Data)
+	require.Equal(t, []string{"asc"}, orders)
+
+	orders = nil
+	byUser, err := client.ListOrganizationMembershipsByUser(
+		context.Background(),
+		[]string{"user_123"},
+		ListOrganizationMembershipsOpts{Order: Asc},
+	)
+	require.NoError(t, err)
+	require.Equal(t, memberships, byUser["user_123"])
+	require.Equal(t, []string{"asc", "asc"}, orders)
+}
+
+func TestListOrganizationMembershipsRoleSlug(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+
+		json.NewEncoder(w).Encode(ListOrganizationMembershipsResponse{
+			Data: []OrganizationMembership{
+				{ID: "om_1", OrganizationID: "org_123", Role: RoleResponse{Slug: "admin"}},
+				{ID: "om_2", OrganizationID: "org_123", Role: RoleResponse{Slug: "member"}},
+				{ID: "om_3", OrganizationID: "org_123", Role: RoleResponse{Slug: "admin"}},
+			},
+			ListMetadata: common.ListMetadata{After: "om_3"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	res, err := client.ListOrganizationMemberships(context.Background(), ListOrganizationMembershipsOpts{
+		OrganizationID: "org_123",
+		RoleSlug:       "admin",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []OrganizationMembership{
+		{ID: "om_1", OrganizationID: "org_123", Role: RoleResponse{Slug: "admin"}},
+		{ID: "om_3", OrganizationID: "org_123", Role: RoleResponse{Slug: "admin"}},
+	}, res.Data)
+	require.Equal(t, "om_3", res.ListMetadata.After)
+
+	require.Len(t, queries, 1)
+	require.Empty(t, queries[0].Get("role"))
+	require.Empty(t, queries[0].Get("role_slug"))
+
+	res, err = client.ListOrganizationMemberships(context.Background(), ListOrganizationMembershipsOpts{
+		OrganizationID: "org_123",
+		RoleSlug:       "owner",
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.Data)
+}
+
+func TestListOrganizationMembershipsByUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(listOrganizationMembershipsByUserTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	memberships, err := client.ListOrganizationMembershipsByUser(
+		context.Background(),
+		[]string{"user_123", "user_456"},
+		ListOrganizationMembershipsOpts{},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string][]OrganizationMembership{
+		"user_123": {
+			{ID: "om_1", UserID: "user_123", OrganizationID: "org_1"},
+			{ID: "om_2", UserID: "user_123", OrganizationID: "org_2"},
+		},
+		"user_456": {
+			{ID: "om_3", UserID: "user_456", OrganizationID: "org_1"},
+		},
+	}, memberships)
+}
+
+func listOrganizationMembershipsByUserTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	var res ListOrganizationMembershipsResponse
+
+	q := r.URL.Query()
+	switch {
+	case q.Get("user_id") == "user_123" && q.Get("after") == "":
+		res.Data = []OrganizationMembership{{ID: "om_1", UserID: "user_123", OrganizationID: "org_1"}}
+		res.ListMetadata.After = "om_1"
+	case q.Get("user_id") == "user_123" && q.Get("after") == "om_1":
+		res.Data = []OrganizationMembership{{ID: "om_2", UserID: "user_123", OrganizationID: "org_2"}}
+	case q.Get("user_id") == "user_456":
+		res.Data = []OrganizationMembership{{ID: "om_3", UserID: "user_456", OrganizationID: "org_1"}}
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func TestListUserOrganizations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(listUserOrganizationsTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	organizations, err := client.ListUserOrganizations(context.Background(), "user_123")
+	require.NoError(t, err)
+	require.Equal(t, []OrganizationSummary{
+		{ID: "org_1", Name: "Foo Corp"},
+		{ID: "org_2", Name: "Bar Corp"},
+	}, organizations)
+
+	organizations, err = client.ListUserOrganizations(context.Background(), "user_789")
+	require.NoError(t, err)
+	require.Empty(t, organizations)
+
+	_, err = client.ListUserOrganizations(context.Background(), "")
+	require.Error(t, err)
+}
+
+func TestListUserOrganizationsIncludeInactive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(listUserOrganizationsTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	organizations, err := client.ListUserOrganizations(context.Background(), "user_999")
+	require.NoError(t, err)
+	require.Equal(t, []OrganizationSummary{
+		{ID: "org_1", Name: "Foo Corp"},
+	}, organizations)
+
+	organizations, err = client.ListUserOrganizationsWithOpts(context.Background(), "user_999", ListUserOrganizationsOpts{
+		IncludeInactive: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []OrganizationSummary{
+		{ID: "org_1", Name: "Foo Corp"},
+		{ID: "org_2", Name: "Bar Corp"},
+	}, organizations)
+}
+
+func TestIsActiveMember(t *testing.T) {
+	tests := []struct {
+		scenario       string
+		organizationID string
+		expected       bool
+	}{
+		{
+			scenario:       "Active membership",
+			organizationID: "org_active",
+			expected:       true,
+		},
+		{
+			scenario:       "Inactive membership",
+			organizationID: "org_inactive",
+		},
+		{
+			scenario:       "Pending membership",
+			organizationID: "org_pending",
+		},
+		{
+			scenario:       "Missing membership",
+			organizationID: "org_other",
+		},
+	}
+
+	statuses := map[string]OrganizationMembershipStatus{
+		"org_active":   OrganizationMembershipActive,
+		"org_inactive": OrganizationMembershipInactive,
+		"org_pending":  OrganizationMembershipPending,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		res := ListOrganizationMembershipsResponse{Data: []OrganizationMembership{}}
+		if status, ok := statuses[query.Get("organization_id")]; ok && query.Get("user_id") == "user_123" {
+			res.Data = append(res.Data, OrganizationMembership{
+				ID:             "om_123",
+				UserID:         "user_123",
+				OrganizationID: query.Get("organization_id"),
+				Status:         status,
+			})
+		}
+
+		body, err := json.Marshal(res)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			active, err := client.IsActiveMember(context.Background(), "user_123", test.organizationID)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, active)
+		})
+	}
+
+	t.Run("Missing arguments return an error", func(t *testing.T) {
+		client := NewClient("test")
+
+		_, err := client.IsActiveMember(context.Background(), "", "org_active")
+		require.Error(t, err)
+
+		_, err = client.IsActiveMember(context.Background(), "user_123", "")
+		require.Error(t, err)
+	})
+}
+
+func TestIsEmailAvailable(t *testing.T) {
+	tests := []struct {
+		scenario string
+		email    string
+		expected bool
+		err      bool
+	}{
+		{
+			scenario: "Taken email",
+			email:    "marcelina@foo-corp.com",
+		},
+		{
+			scenario: "Available email",
+			email:    "new@foo-corp.com",
+			expected: true,
+		},
+		{
+			scenario: "Request failure",
+			email:    "broken@foo-corp.com",
+			err:      true,
+		},
+		{
+			scenario: "Missing email",
+			err:      true,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email := r.URL.Query().Get("email")
+		if email == "broken@foo-corp.com" {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		res := ListUsersResponse{Data: []User{}}
+		if email == "marcelina@foo-corp.com" {
+			res.Data = append(res.Data, User{ID: "user_123", Email: email})
+		}
+
+		body, err := json.Marshal(res)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			available, err := client.IsEmailAvailable(context.Background(), test.email)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, available)
+		})
+	}
+}
+
+func listUserOrganizationsTestHandler(w http.ResponseWriter, r *http.Request) {
+	names := map[string]string{
+		"/organizations/org_1": "Foo Corp",
+		"/organizations/org_2": "Bar Corp",
+	}
+
+	if name, ok := names[r.URL.Path]; ok {
+		if r.Header.Get("Authorization") != "Bearer test" {
+			http.Error(w, "bad auth", http.StatusUnauthorized)
+			return
+		}
 
-	if r.URL.Path == "/user_management/organization_memberships" {
-		body, err = json.Marshal(struct {
-			ListOrganizationMembershipsResponse
-		}{
-			ListOrganizationMembershipsResponse: ListOrganizationMembershipsResponse{
-				Data: []OrganizationMembership{
-					{
-						ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
-						UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
-						OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
-						CreatedAt:      "2021-06-25T19:07:33.155Z",
-						UpdatedAt:      "2021-06-25T19:07:33.155Z",
-					},
-				},
-				ListMetadata: common.ListMetadata{
-					After: "",
-				},
-			},
+		body, _ := json.Marshal(map[string]string{
+			"object": "organization",
+			"id":     strings.TrimPrefix(r.URL.Path, "/organizations/"),
+			"name":   name,
 		})
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+		return
 	}
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+
+	if r.URL.Query().Get("user_id") == "user_999" {
+		memberships := []OrganizationMembership{
+			{ID: "om_1", UserID: "user_999", OrganizationID: "org_1", Status: OrganizationMembershipActive},
+			{ID: "om_2", UserID: "user_999", OrganizationID: "org_2", Status: OrganizationMembershipInactive},
+		}
+
+		var res ListOrganizationMembershipsResponse
+		statuses := strings.Split(r.URL.Query().Get("statuses"), ",")
+		for _, membership := range memberships {
+			for _, status := range statuses {
+				if string(membership.Status) == status {
+					res.Data = append(res.Data, membership)
+				}
+			}
+		}
+
+		body, _ := json.Marshal(res)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write(body)
+	listOrganizationMembershipsByUserTestHandler(w, r)
 }
 
 func TestCreateOrganizationMembership(t *testing.T) {
@@ -1719,7 +3863,7 @@ func TestCreateOrganizationMembership(t *testing.T) {
 			err:      true,
 		},
 		{
-			scenario: "Request returns OrganizationMembership",
+			scenario: "Request without a role returns OrganizationMembership with the default role",
 			client:   NewClient("test"),
 			options: CreateOrganizationMembershipOpts{
 				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
@@ -1729,8 +3873,30 @@ func TestCreateOrganizationMembership(t *testing.T) {
 				ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
 				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
 				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
-				CreatedAt:      "2021-06-25T19:07:33.155Z",
-				UpdatedAt:      "2021-06-25T19:07:33.155Z",
+				Role: RoleResponse{
+					Slug: "member",
+				},
+				CreatedAt: "2021-06-25T19:07:33.155Z",
+				UpdatedAt: "2021-06-25T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Request with a role returns OrganizationMembership with the role",
+			client:   NewClient("test"),
+			options: CreateOrganizationMembershipOpts{
+				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				RoleSlug:       "admin",
+			},
+			expected: OrganizationMembership{
+				ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
+				UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
+				OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+				Role: RoleResponse{
+					Slug: "admin",
+				},
+				CreatedAt: "2021-06-25T19:07:33.155Z",
+				UpdatedAt: "2021-06-25T19:07:33.155Z",
 			},
 		},
 	}
@@ -1766,12 +3932,25 @@ func createOrganizationMembershipTestHandler(w http.ResponseWriter, r *http.Requ
 	var err error
 
 	if r.URL.Path == "/user_management/organization_memberships" {
+		var opts CreateOrganizationMembershipOpts
+		if err = json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if opts.RoleSlug == "" {
+			// The Organization's default role.
+			opts.RoleSlug = "member"
+		}
+
 		body, err = json.Marshal(OrganizationMembership{
 			ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
 			UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
 			OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
-			CreatedAt:      "2021-06-25T19:07:33.155Z",
-			UpdatedAt:      "2021-06-25T19:07:33.155Z",
+			Role: RoleResponse{
+				Slug: opts.RoleSlug,
+			},
+			CreatedAt: "2021-06-25T19:07:33.155Z",
+			UpdatedAt: "2021-06-25T19:07:33.155Z",
 		})
 	}
 
@@ -1931,6 +4110,135 @@ func getInvitationTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestFindInvitationByToken(t *testing.T) {
+	tests := []struct {
+		scenario string
+		client   *Client
+		options  FindInvitationByTokenOpts
+		expected Invitation
+		err      bool
+	}{
+		{
+			scenario: "Request without API Key returns an error",
+			client:   NewClient(""),
+			options:  FindInvitationByTokenOpts{InvitationToken: "myToken"},
+			err:      true,
+		},
+		{
+			scenario: "Request without token returns an error",
+			client:   NewClient("test"),
+			err:      true,
+		},
+		{
+			scenario: "Request returns Invitation by token",
+			client:   NewClient("test"),
+			options:  FindInvitationByTokenOpts{InvitationToken: "myToken"},
+			expected: Invitation{
+				ID:        "invitation_123",
+				Email:     "marcelina@foo-corp.com",
+				State:     Pending,
+				Token:     "myToken",
+				ExpiresAt: "2021-06-25T19:07:33.155Z",
+				CreatedAt: "2021-06-25T19:07:33.155Z",
+				UpdatedAt: "2021-06-25T19:07:33.155Z",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(findInvitationByTokenTestHandler))
+			defer server.Close()
+
+			client := test.client
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			invitation, err := client.FindInvitationByToken(context.Background(), test.options)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, invitation)
+		})
+	}
+}
+
+func TestFindInvitationByTokenEscapesToken(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	// Tokens are opaque: they are sent to the API whatever their characters.
+	_, err := client.FindInvitationByToken(context.Background(), FindInvitationByTokenOpts{InvitationToken: "my Token/../users"})
+	require.Equal(t, http.StatusNotFound, err.(workos_errors.HTTPError).Code)
+	require.Equal(t, []string{"/user_management/invitations/by_token/my%20Token%2F..%2Fusers"}, paths)
+
+	_, err = client.FindInvitationByToken(context.Background(), FindInvitationByTokenOpts{})
+	require.Equal(t, ErrMissingInvitationToken, err)
+	require.Len(t, paths, 1)
+}
+
+func TestInvitationFromRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(findInvitationByTokenTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	req := httptest.NewRequest(http.MethodGet, "/invite?invitation_token=myToken", nil)
+	invitation, err := client.InvitationFromRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, "invitation_123", invitation.ID)
+
+	req = httptest.NewRequest(http.MethodGet, "/invite", nil)
+	_, err = client.InvitationFromRequest(req)
+	require.Equal(t, ErrMissingInvitationToken, err)
+
+	req = httptest.NewRequest(http.MethodGet, "/invite?invitation_token=unknown", nil)
+	_, err = client.InvitationFromRequest(req)
+	require.Equal(t, http.StatusNotFound, err.(workos_errors.HTTPError).Code)
+}
+
+func findInvitationByTokenTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Path != "/user_management/invitations/by_token/myToken" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	body, err := json.Marshal(Invitation{
+		ID:        "invitation_123",
+		Email:     "marcelina@foo-corp.com",
+		State:     Pending,
+		Token:     "myToken",
+		ExpiresAt: "2021-06-25T19:07:33.155Z",
+		CreatedAt: "2021-06-25T19:07:33.155Z",
+		UpdatedAt: "2021-06-25T19:07:33.155Z",
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
 func TestListInvitations(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -2028,6 +4336,122 @@ func listInvitationsTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+func TestSendInvitationExpiresInDays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(SendInvitationTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	for _, days := range []int{-1, 31} {
+		_, err := client.SendInvitation(context.Background(), SendInvitationOpts{
+			Email:         "marcelina@foo-corp.com",
+			ExpiresInDays: days,
+		})
+		require.EqualError(t, err, fmt.Sprintf("invalid arguments: ExpiresInDays must be between 1 and 30, got %d", days))
+	}
+
+	for _, days := range []int{0, 1, 30} {
+		_, err := client.SendInvitation(context.Background(), SendInvitationOpts{
+			Email:         "marcelina@foo-corp.com",
+			ExpiresInDays: days,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestSendInvitationMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var opts SendInvitationOpts
+		json.NewDecoder(r.Body).Decode(&opts)
+
+		json.NewEncoder(w).Encode(Invitation{
+			ID:       "invitation_123",
+			Email:    opts.Email,
+			State:    Pending,
+			Metadata: opts.Metadata,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	metadata := map[string]string{"referral_source": "newsletter", "plan": "enterprise"}
+
+	invitation, err := client.SendInvitation(context.Background(), SendInvitationOpts{
+		Email:    "marcelina@foo-corp.com",
+		Metadata: metadata,
+	})
+	require.NoError(t, err)
+	require.Equal(t, metadata, invitation.Metadata)
+}
+
+func TestInvitationExpiresAtTime(t *testing.T) {
+	invitation := Invitation{ExpiresAt: "2021-06-25T21:07:33.155+02:00"}
+
+	expiresAt, err := invitation.ExpiresAtTime()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2021, 6, 25, 19, 7, 33, 155000000, time.UTC), expiresAt)
+
+	_, err = Invitation{}.ExpiresAtTime()
+	require.Error(t, err)
+}
+
+func TestListPendingInvitations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(listPendingInvitationsTestHandler))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	invitations, err := client.ListPendingInvitations(context.Background(), "org_123")
+	require.NoError(t, err)
+	require.Equal(t, []Invitation{
+		{ID: "invitation_1", State: Pending, OrganizationID: "org_123"},
+		{ID: "invitation_4", State: Pending, OrganizationID: "org_123"},
+	}, invitations)
+
+	_, err = client.ListPendingInvitations(context.Background(), "")
+	require.Error(t, err)
+}
+
+func listPendingInvitationsTestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("organization_id") != "org_123" {
+		http.Error(w, "bad organization", http.StatusBadRequest)
+		return
+	}
+
+	res := ListInvitationsResponse{
+		Data: []Invitation{
+			{ID: "invitation_1", State: Pending, OrganizationID: "org_123"},
+			{ID: "invitation_2", State: Accepted, OrganizationID: "org_123"},
+		},
+		ListMetadata: common.ListMetadata{After: "invitation_2"},
+	}
+	if r.URL.Query().Get("after") == "invitation_2" {
+		res = ListInvitationsResponse{
+			Data: []Invitation{
+				{ID: "invitation_3", State: Revoked, OrganizationID: "org_123"},
+				{ID: "invitation_4", State: Pending, OrganizationID: "org_123"},
+				{ID: "invitation_5", State: Expired, OrganizationID: "org_123"},
+			},
+		}
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
 func TestSendInvitation(t *testing.T) {
 	tests := []struct {
 		scenario string
@@ -2197,3 +4621,156 @@ func RevokeInvitationTestHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(body)
 }
+
+func TestResendInvitation(t *testing.T) {
+	resent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test" {
+			http.Error(w, "bad auth", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/user_management/invitations/invitation_123/resend" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		resent++
+
+		body, err := json.Marshal(Invitation{
+			ID:        "invitation_123",
+			Email:     "marcelina@foo-corp.com",
+			State:     Pending,
+			Token:     "myToken",
+			Metadata:  map[string]string{"team": "design"},
+			ExpiresAt: "2021-07-02T19:07:33.155Z",
+			CreatedAt: "2021-06-25T19:07:33.155Z",
+			UpdatedAt: "2021-06-26T19:07:33.155Z",
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	invitation, err := client.ResendInvitation(context.Background(), ResendInvitationOpts{
+		Invitation: "invitation_123",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, resent)
+	require.Equal(t, "invitation_123", invitation.ID)
+	require.Equal(t, "myToken", invitation.Token)
+	require.Equal(t, map[string]string{"team": "design"}, invitation.Metadata)
+
+	_, err = client.ResendInvitation(context.Background(), ResendInvitationOpts{})
+	require.Error(t, err)
+	require.Equal(t, 1, resent)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	tests := []struct {
+		scenario string
+		client   *Client
+		options  AcceptInvitationOpts
+		expected Invitation
+		err      error
+	}{
+		{
+			scenario: "Request returns accepted Invitation",
+			client:   NewClient("test"),
+			options: AcceptInvitationOpts{
+				Invitation: "invitation_123",
+			},
+			expected: Invitation{
+				ID:         "invitation_123",
+				Email:      "marcelina@foo-corp.com",
+				State:      Accepted,
+				AcceptedAt: "2021-06-25T19:07:33.155Z",
+				ExpiresAt:  "2021-06-25T19:07:33.155Z",
+				CreatedAt:  "2021-06-25T19:07:33.155Z",
+				UpdatedAt:  "2021-06-25T19:07:33.155Z",
+			},
+		},
+		{
+			scenario: "Request for an accepted Invitation returns ErrInvitationNotPending",
+			client:   NewClient("test"),
+			options: AcceptInvitationOpts{
+				Invitation: "invitation_accepted",
+			},
+			err: ErrInvitationNotPending,
+		},
+		{
+			scenario: "Request for an expired Invitation returns ErrInvitationExpired",
+			client:   NewClient("test"),
+			options: AcceptInvitationOpts{
+				Invitation: "invitation_expired",
+			},
+			err: ErrInvitationExpired,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(acceptInvitationTestHandler))
+			defer server.Close()
+
+			client := test.client
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			invitation, err := client.AcceptInvitation(context.Background(), test.options)
+			if test.err != nil {
+				require.True(t, errors.Is(err, test.err))
+
+				var httpError workos_errors.HTTPError
+				require.True(t, errors.As(err, &httpError))
+				require.Equal(t, http.StatusBadRequest, httpError.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, invitation)
+		})
+	}
+}
+
+func acceptInvitationTestHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer test" {
+		http.Error(w, "bad auth", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/user_management/invitations/invitation_accepted/accept":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"invitation_not_pending","message":"Invitation has already been accepted."}`))
+	case "/user_management/invitations/invitation_expired/accept":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"invitation_expired","message":"Invitation has expired."}`))
+	case "/user_management/invitations/invitation_123/accept":
+		body, err := json.Marshal(Invitation{
+			ID:         "invitation_123",
+			Email:      "marcelina@foo-corp.com",
+			State:      Accepted,
+			AcceptedAt: "2021-06-25T19:07:33.155Z",
+			ExpiresAt:  "2021-06-25T19:07:33.155Z",
+			CreatedAt:  "2021-06-25T19:07:33.155Z",
+			UpdatedAt:  "2021-06-25T19:07:33.155Z",
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
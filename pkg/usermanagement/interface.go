@@ -0,0 +1,74 @@
+package usermanagement
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// UserManagement is the interface implemented by Client. Code calling the User
+// Management API can depend on it instead of Client, so that a mock can be
+// injected in tests.
+type UserManagement interface {
+	GetUser(ctx context.Context, opts GetUserOpts) (User, error)
+	GetUsers(ctx context.Context, ids []string) (map[string]User, []error)
+	ListUsers(ctx context.Context, opts ListUsersOpts) (ListUsersResponse, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	IsEmailAvailable(ctx context.Context, email string) (bool, error)
+	IncrementalUserSync(ctx context.Context, since time.Time) ([]User, error)
+	CreateUser(ctx context.Context, opts CreateUserOpts) (User, error)
+	UpdateUser(ctx context.Context, opts UpdateUserOpts) (User, error)
+	DeleteUser(ctx context.Context, opts DeleteUserOpts) error
+	DeleteUserWithMemberships(ctx context.Context, opts DeleteUserOpts) ([]OrganizationMembership, error)
+
+	GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, error)
+	GetLogoutURL(opts GetLogoutURLOpts) (*url.URL, error)
+	AuthenticateWithPassword(ctx context.Context, opts AuthenticateWithPasswordOpts) (AuthenticateResponse, error)
+	AuthenticateWithCode(ctx context.Context, opts AuthenticateWithCodeOpts) (AuthenticateResponse, error)
+	AuthenticateWithMagicAuth(ctx context.Context, opts AuthenticateWithMagicAuthOpts) (AuthenticateResponse, error)
+	AuthenticateWithTOTP(ctx context.Context, opts AuthenticateWithTOTPOpts) (AuthenticateResponse, error)
+	AuthenticateWithEmailVerificationCode(ctx context.Context, opts AuthenticateWithEmailVerificationCodeOpts) (AuthenticateResponse, error)
+	AuthenticateWithOrganizationSelection(ctx context.Context, opts AuthenticateWithOrganizationSelectionOpts) (AuthenticateResponse, error)
+	AuthenticateWithRefreshToken(ctx context.Context, opts AuthenticateWithRefreshTokenOpts) (AuthenticateResponse, error)
+
+	SendVerificationEmail(ctx context.Context, opts SendVerificationEmailOpts) (UserResponse, error)
+	VerifyEmail(ctx context.Context, opts VerifyEmailOpts) (UserResponse, error)
+	SendPasswordResetEmail(ctx context.Context, opts SendPasswordResetEmailOpts) error
+	ResetPassword(ctx context.Context, opts ResetPasswordOpts) (UserResponse, error)
+	SendMagicAuthCode(ctx context.Context, opts SendMagicAuthCodeOpts) error
+	EnrollAuthFactor(ctx context.Context, opts EnrollAuthFactorOpts) (EnrollAuthFactorResponse, error)
+	ListAuthFactors(ctx context.Context, opts ListAuthFactorsOpts) (ListAuthFactorsResponse, error)
+
+	GetOrganizationMembership(ctx context.Context, opts GetOrganizationMembershipOpts) (OrganizationMembership, error)
+	ListOrganizationRoles(ctx context.Context, opts ListOrganizationRolesOpts) (ListOrganizationRolesResponse, error)
+	ListOrganizationMemberships(ctx context.Context, opts ListOrganizationMembershipsOpts) (ListOrganizationMembershipsResponse, error)
+	ListOrganizationMembershipsByUser(ctx context.Context, userIDs []string, opts ListOrganizationMembershipsOpts) (map[string][]OrganizationMembership, error)
+	ListOrganizationMembersWithRoles(ctx context.Context, organizationID string) ([]MembershipWithRole, error)
+	IsActiveMember(ctx context.Context, userID string, organizationID string) (bool, error)
+	ListUserOrganizations(ctx context.Context, userID string) ([]OrganizationSummary, error)
+	ListUserOrganizationsWithOpts(ctx context.Context, userID string, opts ListUserOrganizationsOpts) ([]OrganizationSummary, error)
+	CreateOrganizationMembership(ctx context.Context, opts CreateOrganizationMembershipOpts) (OrganizationMembership, error)
+	CreateOrganizationMemberships(ctx context.Context, opts []CreateOrganizationMembershipOpts) BulkResult
+	UpdateOrganizationMembership(ctx context.Context, opts UpdateOrganizationMembershipOpts) (OrganizationMembership, error)
+	DeleteOrganizationMembership(ctx context.Context, opts DeleteOrganizationMembershipOpts) error
+
+	GetInvitation(ctx context.Context, opts GetInvitationOpts) (Invitation, error)
+	FindInvitationByToken(ctx context.Context, opts FindInvitationByTokenOpts) (Invitation, error)
+	InvitationFromRequest(r *http.Request) (Invitation, error)
+	ListInvitations(ctx context.Context, opts ListInvitationsOpts) (ListInvitationsResponse, error)
+	ListPendingInvitations(ctx context.Context, organizationID string) ([]Invitation, error)
+	SendInvitation(ctx context.Context, opts SendInvitationOpts) (Invitation, error)
+	SendInvitations(ctx context.Context, opts []SendInvitationOpts) BulkResult
+	RevokeInvitation(ctx context.Context, opts RevokeInvitationOpts) (Invitation, error)
+	RevokeInvitations(ctx context.Context, ids []string) BulkResult
+	ResendInvitation(ctx context.Context, opts ResendInvitationOpts) (Invitation, error)
+	AcceptInvitation(ctx context.Context, opts AcceptInvitationOpts) (Invitation, error)
+
+	GetJWKSURL(clientID string) (*url.URL, error)
+	VerifyAccessToken(ctx context.Context, clientID string, accessToken string) (AccessTokenClaims, error)
+	GetUserFromToken(ctx context.Context, clientID string, accessToken string) (User, error)
+	RefreshJWKS(ctx context.Context, clientID string) error
+	GetJWKSJSON(ctx context.Context, clientID string) ([]byte, error)
+	EnsureValidSession(ctx context.Context, session Session, clientID string) (Session, bool, error)
+}
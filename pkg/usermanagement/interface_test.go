@@ -0,0 +1,6 @@
+package usermanagement
+
+var (
+	_ UserManagement = (*Client)(nil)
+	_ UserManagement = (*CachingClient)(nil)
+)
@@ -0,0 +1,391 @@
+package usermanagement
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/workos/workos-go/v3/internal/workos"
+)
+
+// This represents the list of errors that could be raised when verifying an
+// access token.
+var (
+	ErrInvalidAccessToken = errors.New("access token is invalid")
+	ErrAccessTokenExpired = errors.New("access token is expired")
+)
+
+// JSONWebKey represents a public key of a JSON Web Key Set.
+type JSONWebKey struct {
+	// The key type. WorkOS signs access tokens with RSA keys.
+	Kty string `json:"kty"`
+
+	// The key identifier, referenced by the `kid` header of access tokens.
+	Kid string `json:"kid"`
+
+	// The algorithm the key is used with.
+	Alg string `json:"alg"`
+
+	// The intended use of the key.
+	Use string `json:"use"`
+
+	// The base64url encoded RSA modulus.
+	N string `json:"n"`
+
+	// The base64url encoded RSA public exponent.
+	E string `json:"e"`
+}
+
+// JSONWebKeySet represents the set of public keys used to sign access tokens.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// GetJWKSURL returns the URL of the JSON Web Key Set used to sign the access
+// tokens issued for the given client.
+func (c *Client) GetJWKSURL(clientID string) (*url.URL, error) {
+	if clientID == "" {
+		return nil, errors.New("incomplete arguments: missing ClientID")
+	}
+
+	return url.ParseRequestURI(c.endpoint() + "/sso/jwks/" + clientID)
+}
+
+// VerifyAccessToken verifies the signature of an access token issued by WorkOS
+// for the given client and returns its claims.
+//
+// The JSON Web Key Set of the client is fetched on first use and cached by the
+// Client. It is fetched again when a token is signed with an unknown key, eg.
+// after a key rotation, at most once per JWKSMinRefreshInterval.
+//
+// When the token is correctly signed but expired, its claims are returned
+// along with ErrAccessTokenExpired. A token without an exp claim, or with a
+// zero one, never expires and is accepted for as long as its signature is
+// valid.
+func (c *Client) VerifyAccessToken(ctx context.Context, clientID string, accessToken string) (AccessTokenClaims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return AccessTokenClaims{}, ErrInvalidAccessToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return AccessTokenClaims{}, ErrInvalidAccessToken
+	}
+	if err = json.Unmarshal(rawHeader, &header); err != nil || header.Alg != "RS256" {
+		return AccessTokenClaims{}, ErrInvalidAccessToken
+	}
+
+	key, err := c.jwksKey(ctx, clientID, header.Kid)
+	if err != nil {
+		return AccessTokenClaims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return AccessTokenClaims{}, ErrInvalidAccessToken
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return AccessTokenClaims{}, ErrInvalidAccessToken
+	}
+
+	claims, err := decodeAccessTokenClaims(accessToken)
+	if err != nil {
+		return AccessTokenClaims{}, ErrInvalidAccessToken
+	}
+
+	if claims.ExpiresAt != 0 && !c.now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return claims, ErrAccessTokenExpired
+	}
+	return claims, nil
+}
+
+// JWKSCache stores the keys of JSON Web Key Sets so that they can be shared by
+// several Clients, eg. across the instances of a service with a Redis backed
+// implementation. Implementations must be safe for concurrent use.
+type JWKSCache interface {
+	// Get returns the key identified by kid for the given client, and whether
+	// it was found.
+	Get(ctx context.Context, clientID string, kid string) (JSONWebKey, bool, error)
+
+	// Set stores the key identified by kid for the given client. When ttl is
+	// not zero, the key should be evicted after ttl.
+	Set(ctx context.Context, clientID string, kid string, key JSONWebKey, ttl time.Duration) error
+}
+
+// GetUserFromToken verifies an access token issued by WorkOS for the given
+// client and gets the User it was issued to.
+//
+// The token is verified before the User is fetched: an invalid or expired
+// token returns ErrInvalidAccessToken or ErrAccessTokenExpired without
+// calling the API.
+func (c *Client) GetUserFromToken(ctx context.Context, clientID string, accessToken string) (User, error) {
+	claims, err := c.VerifyAccessToken(ctx, clientID, accessToken)
+	if err != nil {
+		return User{}, err
+	}
+	if claims.Subject == "" {
+		return User{}, ErrInvalidAccessToken
+	}
+
+	return c.GetUser(ctx, GetUserOpts{User: claims.Subject})
+}
+
+// cachedJWKS is a JSON Web Key Set cached by the Client.
+type cachedJWKS struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// RefreshJWKS fetches the JSON Web Key Set of the given client again, replacing
+// the cached one. It is meant to be called when the signing keys are rotated,
+// so that tokens signed with a new key are accepted right away.
+func (c *Client) RefreshJWKS(ctx context.Context, clientID string) error {
+	c.jwksMu.Lock()
+	defer c.jwksMu.Unlock()
+
+	if c.JWKSCache != nil {
+		_, err := c.refreshSharedJWKS(ctx, clientID)
+		return err
+	}
+
+	_, err := c.refreshJWKS(ctx, clientID)
+	return err
+}
+
+// DefaultJWKSMinRefreshInterval is the minimum duration between two fetches
+// of the JSON Web Key Set of a client triggered by unknown key identifiers,
+// when the Client does not configure JWKSMinRefreshInterval.
+const DefaultJWKSMinRefreshInterval = time.Minute
+
+func (c *Client) jwksMinRefreshInterval() time.Duration {
+	if c.JWKSMinRefreshInterval > 0 {
+		return c.JWKSMinRefreshInterval
+	}
+	return DefaultJWKSMinRefreshInterval
+}
+
+// jwksKey returns the public key identified by kid in the JSON Web Key Set of
+// the given client, fetching the set when it is not cached yet or when the
+// cached one is older than JWKSCacheTTL.
+//
+// An unknown kid, eg. after WorkOS rotated its signing keys, fetches the set
+// again, at most once per JWKSMinRefreshInterval: kids that are still unknown
+// are rejected without fetching until then. Concurrent calls wait for the
+// fetch in progress instead of starting their own.
+func (c *Client) jwksKey(ctx context.Context, clientID string, kid string) (*rsa.PublicKey, error) {
+	if c.JWKSCache != nil {
+		return c.sharedJWKSKey(ctx, clientID, kid)
+	}
+
+	c.jwksMu.Lock()
+	defer c.jwksMu.Unlock()
+
+	cached, ok := c.jwks[clientID]
+	if !ok || (c.JWKSCacheTTL > 0 && c.now().Sub(cached.fetchedAt) >= c.JWKSCacheTTL) {
+		var err error
+		if cached, err = c.refreshJWKS(ctx, clientID); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok := cached.keys[kid]
+	if !ok && c.now().Sub(cached.fetchedAt) >= c.jwksMinRefreshInterval() {
+		var err error
+		if cached, err = c.refreshJWKS(ctx, clientID); err != nil {
+			return nil, err
+		}
+		key, ok = cached.keys[kid]
+	}
+	if !ok {
+		return nil, ErrInvalidAccessToken
+	}
+	return key, nil
+}
+
+// refreshJWKS fetches and caches the JSON Web Key Set of the given client. The
+// caller must hold jwksMu.
+func (c *Client) refreshJWKS(ctx context.Context, clientID string) (cachedJWKS, error) {
+	set, err := c.fetchJWKS(ctx, clientID)
+	if err != nil {
+		return cachedJWKS{}, err
+	}
+
+	keys, err := parseJWKS(set)
+	if err != nil {
+		return cachedJWKS{}, err
+	}
+
+	if c.jwks == nil {
+		c.jwks = make(map[string]cachedJWKS)
+	}
+	cached := cachedJWKS{keys: keys, fetchedAt: c.now()}
+	c.jwks[clientID] = cached
+	return cached, nil
+}
+
+// sharedJWKSKey returns the public key identified by kid for the given client
+// from JWKSCache, fetching the JSON Web Key Set into the cache when the key is
+// missing.
+func (c *Client) sharedJWKSKey(ctx context.Context, clientID string, kid string) (*rsa.PublicKey, error) {
+	key, ok, err := c.JWKSCache.Get(ctx, clientID, kid)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if key.Kty != "RSA" {
+			return nil, ErrInvalidAccessToken
+		}
+		return parseJWK(key)
+	}
+
+	c.jwksMu.Lock()
+	defer c.jwksMu.Unlock()
+
+	// The kid was not part of the set fetched less than
+	// JWKSMinRefreshInterval ago, or was just fetched by a concurrent call:
+	// answer from that set rather than fetching it again, so that made up kids
+	// are negatively cached without keeping track of each of them.
+	cached, ok := c.sharedJWKS[clientID]
+	if !ok || c.now().Sub(cached.fetchedAt) >= c.jwksMinRefreshInterval() {
+		if cached, err = c.refreshSharedJWKS(ctx, clientID); err != nil {
+			return nil, err
+		}
+	}
+
+	publicKey, ok := cached.keys[kid]
+	if !ok {
+		return nil, ErrInvalidAccessToken
+	}
+	return publicKey, nil
+}
+
+// refreshSharedJWKS fetches the JSON Web Key Set of the given client, stores
+// its keys in JWKSCache and remembers them as the last fetched set. The caller
+// must hold jwksMu.
+func (c *Client) refreshSharedJWKS(ctx context.Context, clientID string) (cachedJWKS, error) {
+	set, err := c.fetchJWKS(ctx, clientID)
+	if err != nil {
+		return cachedJWKS{}, err
+	}
+
+	keys, err := parseJWKS(set)
+	if err != nil {
+		return cachedJWKS{}, err
+	}
+
+	for _, k := range set.Keys {
+		if err := c.JWKSCache.Set(ctx, clientID, k.Kid, k, c.JWKSCacheTTL); err != nil {
+			return cachedJWKS{}, err
+		}
+	}
+
+	if c.sharedJWKS == nil {
+		c.sharedJWKS = make(map[string]cachedJWKS)
+	}
+	cached := cachedJWKS{keys: keys, fetchedAt: c.now()}
+	c.sharedJWKS[clientID] = cached
+	return cached, nil
+}
+
+func (c *Client) fetchJWKS(ctx context.Context, clientID string) (JSONWebKeySet, error) {
+	data, err := c.GetJWKSJSON(ctx, clientID)
+	if err != nil {
+		return JSONWebKeySet{}, err
+	}
+
+	var body JSONWebKeySet
+	err = json.Unmarshal(data, &body)
+
+	return body, err
+}
+
+// GetJWKSJSON fetches the JSON Web Key Set used to sign the access tokens
+// issued for the given client and returns it as sent by WorkOS, eg. to verify
+// access tokens with another JWT library such as github.com/lestrrat-go/jwx.
+//
+// Unlike VerifyAccessToken, it neither reads nor fills the cached set: the
+// document is fetched on every call.
+func (c *Client) GetJWKSJSON(ctx context.Context, clientID string) ([]byte, error) {
+	u, err := c.GetJWKSURL(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "workos-go/"+workos.Version)
+
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err = c.tryGetHTTPError(res); err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("invalid JSON Web Key Set: not a JSON document")
+	}
+	return data, nil
+}
+
+func parseJWKS(set JSONWebKeySet) (map[string]*rsa.PublicKey, error) {
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		key, err := parseJWK(k)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func parseJWK(k JSONWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON Web Key %q: %w", k.Kid, err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON Web Key %q: %w", k.Kid, err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
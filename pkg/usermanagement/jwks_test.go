@@ -0,0 +1,564 @@
+package usermanagement
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJWKSURL(t *testing.T) {
+	client := NewClient("test")
+
+	u, err := client.GetJWKSURL("client_123")
+	require.NoError(t, err)
+	require.Equal(t, "https://api.workos.com/sso/jwks/client_123", u.String())
+
+	_, err = client.GetJWKSURL("")
+	require.Error(t, err)
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+	otherKey := newTestSigningKey(t, "key_123")
+
+	claims := map[string]interface{}{
+		"sub":         "user_123",
+		"sid":         "session_123",
+		"org_id":      "org_123",
+		"role":        "admin",
+		"permissions": []string{"posts:read", "posts:write"},
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	}
+
+	expiredClaims := map[string]interface{}{
+		"sub": "user_123",
+		"sid": "session_123",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	}
+
+	tests := []struct {
+		scenario    string
+		accessToken string
+		expected    AccessTokenClaims
+		err         error
+	}{
+		{
+			scenario:    "Valid token returns its claims",
+			accessToken: key.sign(t, claims),
+			expected: AccessTokenClaims{
+				Subject:        "user_123",
+				SessionID:      "session_123",
+				OrganizationID: "org_123",
+				Role:           "admin",
+				Permissions:    []string{"posts:read", "posts:write"},
+				ExpiresAt:      claims["exp"].(int64),
+			},
+		},
+		{
+			scenario:    "Token signed with another key returns an error",
+			accessToken: otherKey.sign(t, claims),
+			err:         ErrInvalidAccessToken,
+		},
+		{
+			scenario:    "Token signed with an unknown key returns an error",
+			accessToken: newTestSigningKey(t, "key_456").sign(t, claims),
+			err:         ErrInvalidAccessToken,
+		},
+		{
+			scenario:    "Malformed token returns an error",
+			accessToken: "not-a-token",
+			err:         ErrInvalidAccessToken,
+		},
+		{
+			scenario:    "Expired token returns its claims and an error",
+			accessToken: key.sign(t, expiredClaims),
+			expected: AccessTokenClaims{
+				Subject:   "user_123",
+				SessionID: "session_123",
+				ExpiresAt: expiredClaims["exp"].(int64),
+			},
+			err: ErrAccessTokenExpired,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			server := httptest.NewServer(jwksTestHandler(key))
+			defer server.Close()
+
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			verified, err := client.VerifyAccessToken(context.Background(), "client_123", test.accessToken)
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.expected, verified)
+		})
+	}
+}
+
+func TestGetUserFromToken(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	tests := []struct {
+		scenario    string
+		accessToken string
+		expected    User
+		err         error
+	}{
+		{
+			scenario: "Valid token returns the User",
+			accessToken: key.sign(t, map[string]interface{}{
+				"sub": "user_123",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}),
+			expected: User{ID: "user_123", Email: "marcelina@foo-corp.com"},
+		},
+		{
+			scenario:    "Invalid token returns an error",
+			accessToken: newTestSigningKey(t, "key_123").sign(t, map[string]interface{}{"sub": "user_123"}),
+			err:         ErrInvalidAccessToken,
+		},
+		{
+			scenario: "Expired token returns an error",
+			accessToken: key.sign(t, map[string]interface{}{
+				"sub": "user_123",
+				"exp": time.Now().Add(-time.Minute).Unix(),
+			}),
+			err: ErrAccessTokenExpired,
+		},
+		{
+			scenario:    "Token without subject returns an error",
+			accessToken: key.sign(t, map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()}),
+			err:         ErrInvalidAccessToken,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			userRequests := 0
+			jwks := jwksTestHandler(key)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/user_management/users/user_123" {
+					jwks.ServeHTTP(w, r)
+					return
+				}
+
+				userRequests++
+				body, err := json.Marshal(User{ID: "user_123", Email: "marcelina@foo-corp.com"})
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			user, err := client.GetUserFromToken(context.Background(), "client_123", test.accessToken)
+			if test.err != nil {
+				require.Equal(t, test.err, err)
+				require.Zero(t, userRequests)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, user)
+		})
+	}
+}
+
+func TestVerifyAccessTokenCachesJWKS(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	requests := 0
+	handler := jwksTestHandler(key)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	accessToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := client.VerifyAccessToken(context.Background(), "client_123", accessToken)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, requests)
+}
+
+func TestRefreshJWKS(t *testing.T) {
+	oldKey := newTestSigningKey(t, "key_123")
+	newKey := newTestSigningKey(t, "key_456")
+
+	handler := jwksTestHandler(oldKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	claims := map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	_, err := client.VerifyAccessToken(context.Background(), "client_123", oldKey.sign(t, claims))
+	require.NoError(t, err)
+
+	// The keys are rotated.
+	handler = jwksTestHandler(newKey)
+
+	_, err = client.VerifyAccessToken(context.Background(), "client_123", newKey.sign(t, claims))
+	require.Equal(t, ErrInvalidAccessToken, err)
+
+	require.NoError(t, client.RefreshJWKS(context.Background(), "client_123"))
+
+	_, err = client.VerifyAccessToken(context.Background(), "client_123", newKey.sign(t, claims))
+	require.NoError(t, err)
+
+	_, err = client.VerifyAccessToken(context.Background(), "client_123", oldKey.sign(t, claims))
+	require.Equal(t, ErrInvalidAccessToken, err)
+}
+
+func TestGetJWKSJSON(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	server := httptest.NewServer(jwksTestHandler(key))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	data, err := client.GetJWKSJSON(context.Background(), "client_123")
+	require.NoError(t, err)
+	require.True(t, json.Valid(data))
+
+	var set map[string][]map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &set))
+	require.Len(t, set["keys"], 1)
+	require.Equal(t, "key_123", set["keys"][0]["kid"])
+	require.Equal(t, "RSA", set["keys"][0]["kty"])
+
+	_, err = client.GetJWKSJSON(context.Background(), "client_456")
+	require.Error(t, err)
+
+	_, err = client.GetJWKSJSON(context.Background(), "")
+	require.Error(t, err)
+}
+
+func TestVerifyAccessTokenKeyRotation(t *testing.T) {
+	oldKey := newTestSigningKey(t, "key_123")
+	newKey := newTestSigningKey(t, "key_456")
+
+	requests := 0
+	handler := jwksTestHandler(oldKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.Now = func() time.Time { return now }
+
+	claims := map[string]interface{}{
+		"sub": "user_123",
+		"exp": now.Add(time.Hour).Unix(),
+	}
+
+	_, err := client.VerifyAccessToken(context.Background(), "client_123", oldKey.sign(t, claims))
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// The keys are rotated.
+	handler = jwksTestHandler(oldKey, newKey)
+
+	// Unknown kids do not fetch the set again within the minimum interval.
+	for _, kid := range []string{"key_456", "key_789", "key_000"} {
+		_, err = client.VerifyAccessToken(context.Background(), "client_123", newTestSigningKey(t, kid).sign(t, claims))
+		require.Equal(t, ErrInvalidAccessToken, err)
+	}
+	require.Equal(t, 1, requests)
+
+	// Once the interval elapsed, an unknown kid fetches the set once.
+	now = now.Add(DefaultJWKSMinRefreshInterval)
+
+	_, err = client.VerifyAccessToken(context.Background(), "client_123", newKey.sign(t, claims))
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+
+	for _, kid := range []string{"key_789", "key_000"} {
+		_, err = client.VerifyAccessToken(context.Background(), "client_123", newTestSigningKey(t, kid).sign(t, claims))
+		require.Equal(t, ErrInvalidAccessToken, err)
+	}
+	require.Equal(t, 2, requests)
+}
+
+func TestVerifyAccessTokenJWKSCacheTTL(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	requests := 0
+	handler := jwksTestHandler(key)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.JWKSCacheTTL = time.Nanosecond
+
+	accessToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := client.VerifyAccessToken(context.Background(), "client_123", accessToken)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, requests)
+}
+
+// testSigningKey is an RSA key used to sign access tokens in tests.
+type testSigningKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func TestVerifyAccessTokenSharedJWKSCache(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	requests := 0
+	handler := jwksTestHandler(key)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cache := &fakeJWKSCache{keys: map[string]JSONWebKey{}}
+
+	newClient := func() *Client {
+		client := NewClient("test")
+		client.Endpoint = server.URL
+		client.HTTPClient = server.Client()
+		client.JWKSCache = cache
+		client.JWKSCacheTTL = time.Hour
+		return client
+	}
+
+	token := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	for i := 0; i < 3; i++ {
+		claims, err := newClient().VerifyAccessToken(context.Background(), "client_123", token)
+		require.NoError(t, err)
+		require.Equal(t, "user_123", claims.Subject)
+	}
+
+	require.Equal(t, 1, requests)
+	require.Equal(t, map[string]JSONWebKey{"client_123/key_123": key.jwk()}, cache.keys)
+	require.Equal(t, time.Hour, cache.ttl)
+
+	_, err := newClient().VerifyAccessToken(context.Background(), "client_123", newTestSigningKey(t, "key_456").sign(t, nil))
+	require.Equal(t, ErrInvalidAccessToken, err)
+}
+
+func TestVerifyAccessTokenSharedJWKSCacheUnknownKids(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+	unknownKey := newTestSigningKey(t, "key_456")
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		jwksTestHandler(key).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	var nowMu sync.Mutex
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+	client.JWKSCache = &fakeJWKSCache{keys: map[string]JSONWebKey{}}
+	client.Now = func() time.Time {
+		nowMu.Lock()
+		defer nowMu.Unlock()
+		return now
+	}
+
+	claims := map[string]interface{}{
+		"sub": "user_123",
+		"exp": now.Add(time.Hour).Unix(),
+	}
+
+	// Concurrent tokens with unknown kids share a single fetch.
+	parts := strings.Split(unknownKey.sign(t, claims), ".")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			header := fmt.Sprintf(`{"alg":"RS256","kid":"key_%d"}`, 1000+i)
+			token := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + parts[1] + "." + parts[2]
+
+			_, errs[i] = client.VerifyAccessToken(context.Background(), "client_123", token)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.Equal(t, ErrInvalidAccessToken, err)
+	}
+	require.Equal(t, 1, requests)
+
+	// The known key was cached by that fetch.
+	_, err := client.VerifyAccessToken(context.Background(), "client_123", key.sign(t, claims))
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// Further unknown kids do not fetch the set within the interval...
+	_, err = client.VerifyAccessToken(context.Background(), "client_123", unknownKey.sign(t, claims))
+	require.Equal(t, ErrInvalidAccessToken, err)
+	require.Equal(t, 1, requests)
+
+	// ...but do once it elapsed.
+	nowMu.Lock()
+	now = now.Add(DefaultJWKSMinRefreshInterval)
+	nowMu.Unlock()
+
+	for i := 0; i < 3; i++ {
+		_, err = client.VerifyAccessToken(context.Background(), "client_123", unknownKey.sign(t, claims))
+		require.Equal(t, ErrInvalidAccessToken, err)
+	}
+	require.Equal(t, 2, requests)
+}
+
+type fakeJWKSCache struct {
+	mu   sync.Mutex
+	keys map[string]JSONWebKey
+	ttl  time.Duration
+}
+
+func (c *fakeJWKSCache) Get(ctx context.Context, clientID string, kid string) (JSONWebKey, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.keys[clientID+"/"+kid]
+	return key, ok, nil
+}
+
+func (c *fakeJWKSCache) Set(ctx context.Context, clientID string, kid string, key JSONWebKey, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.keys[clientID+"/"+kid] = key
+	c.ttl = ttl
+	return nil
+}
+
+func newTestSigningKey(t *testing.T, kid string) testSigningKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return testSigningKey{kid: kid, key: key}
+}
+
+// sign returns an RS256 JWT carrying the given claims.
+func (k testSigningKey) sign(t *testing.T, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": k.kid})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, k.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (k testSigningKey) jwk() JSONWebKey {
+	return JSONWebKey{
+		Kty: "RSA",
+		Kid: k.kid,
+		Alg: "RS256",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+	}
+}
+
+func jwksTestHandler(keys ...testSigningKey) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sso/jwks/client_123" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		var set JSONWebKeySet
+		for _, k := range keys {
+			set.Keys = append(set.Keys, k.jwk())
+		}
+
+		body, err := json.Marshal(set)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+}
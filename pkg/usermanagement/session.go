@@ -0,0 +1,306 @@
+package usermanagement
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SessionCookieName is the name of the cookie holding the sealed Session.
+const SessionCookieName = "wos-session"
+
+var (
+	// ErrInvalidSession is returned when a sealed session cannot be unsealed.
+	ErrInvalidSession = errors.New("session is invalid")
+
+	// ErrMissingCookiePassword is returned when sessions are sealed or
+	// unsealed with an empty password.
+	ErrMissingCookiePassword = errors.New("incomplete arguments: missing password")
+)
+
+// Session contains the tokens of an authenticated user, as stored in the
+// session cookie.
+type Session struct {
+	// The access token of the session.
+	AccessToken string `json:"access_token"`
+
+	// The refresh token used to renew the access token once it expires.
+	RefreshToken string `json:"refresh_token"`
+
+	// The authenticated User.
+	User User `json:"user"`
+
+	// The Organization the session is scoped to, if any.
+	OrganizationID string `json:"organization_id,omitempty"`
+
+	// The Organization the user last selected, remembered with
+	// RememberOrganization so that refreshed sessions are scoped to it.
+	DefaultOrganizationID string `json:"default_organization_id,omitempty"`
+
+	// The time at which the access token expires. Zero when the access token
+	// carries no expiry.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ToSession packages the response into a Session, ready to be sealed. The
+// expiry of the session is read from the access token.
+func (r AuthenticateResponse) ToSession() Session {
+	session := Session{
+		AccessToken:    r.AccessToken,
+		RefreshToken:   r.RefreshToken,
+		User:           r.User,
+		OrganizationID: r.OrganizationID,
+	}
+
+	if claims, err := decodeAccessTokenClaims(r.AccessToken); err == nil && claims.ExpiresAt != 0 {
+		session.ExpiresAt = time.Unix(claims.ExpiresAt, 0).UTC()
+	}
+	return session
+}
+
+// RememberOrganization returns a copy of the session remembering the given
+// Organization, eg. the one the user just selected, as its default. Sessions
+// refreshed by EnsureValidSession and SessionMiddleware are scoped to it and
+// keep remembering it.
+func (s Session) RememberOrganization(organizationID string) Session {
+	s.DefaultOrganizationID = organizationID
+	return s
+}
+
+// DefaultOrganizationID returns the Organization remembered by the session
+// with RememberOrganization, if any.
+func DefaultOrganizationID(session Session) string {
+	return session.DefaultOrganizationID
+}
+
+// SealSession encrypts the session with the given password so that it can be
+// stored in a cookie.
+func SealSession(session Session, password string) (string, error) {
+	if password == "" {
+		return "", ErrMissingCookiePassword
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := sessionCipher(password)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, data, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// UnsealSession decrypts a session sealed with SealSession. It returns
+// ErrMissingCookiePassword when password is empty.
+func UnsealSession(sealed string, password string) (Session, error) {
+	if password == "" {
+		return Session{}, ErrMissingCookiePassword
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return Session{}, ErrInvalidSession
+	}
+
+	aead, err := sessionCipher(password)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return Session{}, ErrInvalidSession
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return Session{}, ErrInvalidSession
+	}
+
+	var session Session
+	if err = json.Unmarshal(plaintext, &session); err != nil {
+		return Session{}, ErrInvalidSession
+	}
+	return session, nil
+}
+
+// sessionKeyInfo binds the keys derived from cookie passwords to sealed
+// sessions, so that they differ from keys derived from the same password for
+// other purposes.
+const sessionKeyInfo = "workos-go session"
+
+func sessionCipher(password string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(hkdfSHA256([]byte(password), []byte(sessionKeyInfo)))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// hkdfSHA256 derives a 32 bytes key from secret with HKDF (RFC 5869) using
+// SHA-256, no salt and the given info.
+func hkdfSHA256(secret []byte, info []byte) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	sessionContextKey
+)
+
+// UserFromContext returns the User injected into the context by
+// SessionMiddleware.
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userContextKey).(User)
+	return user, ok
+}
+
+// SessionFromContext returns the Session injected into the context by
+// SessionMiddleware.
+func SessionFromContext(ctx context.Context) (Session, bool) {
+	session, ok := ctx.Value(sessionContextKey).(Session)
+	return session, ok
+}
+
+// SessionMiddleware returns a middleware that authenticates requests with the
+// sealed session stored in the SessionCookieName cookie.
+//
+// The access token of the session is verified locally. When it is expired, it
+// is renewed with AuthenticateWithRefreshToken and the cookie is sealed again.
+// The authenticated user is then injected into the request context. Requests
+// without a valid session are answered with a 401 status.
+//
+// SessionMiddleware panics when cookiePassword is empty, eg. when it is read
+// from an environment variable that is not set, rather than accepting cookies
+// anyone could seal.
+func SessionMiddleware(client *Client, clientID string, cookiePassword string) func(http.Handler) http.Handler {
+	if cookiePassword == "" {
+		panic("usermanagement: SessionMiddleware called with an empty cookie password")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(SessionCookieName)
+			if err != nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			session, err := UnsealSession(cookie.Value, cookiePassword)
+			if err != nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			session, refreshed, err := client.EnsureValidSession(r.Context(), session, clientID)
+			if err == nil && refreshed {
+				err = setSessionCookie(w, session, cookiePassword)
+			}
+			if err != nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userContextKey, session.User)
+			ctx = context.WithValue(ctx, sessionContextKey, session)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// EnsureValidSession verifies the access token of a session and refreshes the
+// session when the token is expired. It returns the session to use from now on
+// and whether it was refreshed, in which case it should be stored again, eg. in
+// the session cookie.
+//
+// An error is returned when the access token is invalid or when the session
+// could not be refreshed, eg. because its refresh token was revoked.
+// ErrInvalidSession is returned when the access token was not issued to the
+// User of the session.
+func (c *Client) EnsureValidSession(ctx context.Context, session Session, clientID string) (Session, bool, error) {
+	claims, err := c.VerifyAccessToken(ctx, clientID, session.AccessToken)
+	if err != nil && err != ErrAccessTokenExpired {
+		return Session{}, false, err
+	}
+	if claims.Subject != session.User.ID {
+		return Session{}, false, ErrInvalidSession
+	}
+	if err == nil {
+		return session, false, nil
+	}
+
+	refreshed, err := refreshSession(ctx, c, clientID, session)
+	if err != nil {
+		return Session{}, false, err
+	}
+	return refreshed, true, nil
+}
+
+func refreshSession(ctx context.Context, client *Client, clientID string, session Session) (Session, error) {
+	res, err := client.AuthenticateWithRefreshToken(ctx, AuthenticateWithRefreshTokenOpts{
+		ClientID:       clientID,
+		RefreshToken:   session.RefreshToken,
+		OrganizationID: DefaultOrganizationID(session),
+	})
+	if err != nil {
+		return Session{}, err
+	}
+
+	claims, err := client.VerifyAccessToken(ctx, clientID, res.AccessToken)
+	if err != nil {
+		return Session{}, err
+	}
+
+	refreshed := res.ToSession()
+	if refreshed.User.ID == "" {
+		refreshed.User = session.User
+	}
+	if claims.Subject != refreshed.User.ID {
+		return Session{}, ErrInvalidSession
+	}
+	return refreshed.RememberOrganization(session.DefaultOrganizationID), nil
+}
+
+func setSessionCookie(w http.ResponseWriter, session Session, cookiePassword string) error {
+	sealed, err := SealSession(session, cookiePassword)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    sealed,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
@@ -0,0 +1,425 @@
+package usermanagement
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testCookiePassword = "a-cookie-password-of-32-characters"
+
+func TestSealSession(t *testing.T) {
+	session := Session{
+		AccessToken:  "access_token_123",
+		RefreshToken: "refresh_token_123",
+		User:         User{ID: "user_123"},
+	}
+
+	sealed, err := SealSession(session, testCookiePassword)
+	require.NoError(t, err)
+
+	unsealed, err := UnsealSession(sealed, testCookiePassword)
+	require.NoError(t, err)
+	require.Equal(t, session, unsealed)
+
+	_, err = UnsealSession(sealed, "another-password")
+	require.Equal(t, ErrInvalidSession, err)
+
+	_, err = SealSession(session, "")
+	require.Equal(t, ErrMissingCookiePassword, err)
+
+	_, err = UnsealSession(sealed, "")
+	require.Equal(t, ErrMissingCookiePassword, err)
+}
+
+func TestHKDFSHA256(t *testing.T) {
+	// Test case 3 of RFC 5869, truncated to 32 bytes.
+	secret, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	require.Equal(t,
+		"8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d",
+		hex.EncodeToString(hkdfSHA256(secret, nil)),
+	)
+}
+
+func TestSessionMiddlewareWithEmptyPassword(t *testing.T) {
+	require.Panics(t, func() {
+		SessionMiddleware(NewClient("test"), "client_123", "")
+	})
+}
+
+func TestAuthenticateResponseToSession(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	res := AuthenticateResponse{
+		User:           User{ID: "user_123", Email: "marcelina@foo-corp.com"},
+		OrganizationID: "org_123",
+		AccessToken: testAccessToken(t, map[string]interface{}{
+			"sub": "user_123",
+			"exp": expiresAt.Unix(),
+		}),
+		RefreshToken: "refresh_token_123",
+	}
+
+	session := res.ToSession()
+	require.Equal(t, Session{
+		AccessToken:    res.AccessToken,
+		RefreshToken:   "refresh_token_123",
+		User:           User{ID: "user_123", Email: "marcelina@foo-corp.com"},
+		OrganizationID: "org_123",
+		ExpiresAt:      expiresAt,
+	}, session)
+
+	sealed, err := SealSession(session, testCookiePassword)
+	require.NoError(t, err)
+
+	unsealed, err := UnsealSession(sealed, testCookiePassword)
+	require.NoError(t, err)
+	require.Equal(t, session, unsealed)
+}
+
+func TestSessionMiddleware(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	validToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	expiredToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	})
+	refreshedToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"sid": "refreshed",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	user := User{ID: "user_123", Email: "marcelina@foo-corp.com"}
+
+	tests := []struct {
+		scenario        string
+		cookie          string
+		status          int
+		refreshedCookie bool
+	}{
+		{
+			scenario: "Valid session is authenticated",
+			cookie: sealTestSession(t, Session{
+				AccessToken:  validToken,
+				RefreshToken: "refresh_token_123",
+				User:         user,
+			}),
+			status: http.StatusOK,
+		},
+		{
+			scenario: "Expired session is refreshed",
+			cookie: sealTestSession(t, Session{
+				AccessToken:  expiredToken,
+				RefreshToken: "refresh_token_123",
+				User:         user,
+			}),
+			status:          http.StatusOK,
+			refreshedCookie: true,
+		},
+		{
+			scenario: "Expired session with a revoked refresh token is rejected",
+			cookie: sealTestSession(t, Session{
+				AccessToken:  expiredToken,
+				RefreshToken: "revoked_refresh_token",
+				User:         user,
+			}),
+			status: http.StatusUnauthorized,
+		},
+		{
+			scenario: "Session with an invalid access token is rejected",
+			cookie: sealTestSession(t, Session{
+				AccessToken:  newTestSigningKey(t, "key_456").sign(t, map[string]interface{}{"sub": "user_123"}),
+				RefreshToken: "refresh_token_123",
+				User:         user,
+			}),
+			status: http.StatusUnauthorized,
+		},
+		{
+			scenario: "Session claiming another user is rejected",
+			cookie: sealTestSession(t, Session{
+				AccessToken:  validToken,
+				RefreshToken: "refresh_token_123",
+				User:         User{ID: "user_456", Email: "admin@foo-corp.com"},
+			}),
+			status: http.StatusUnauthorized,
+		},
+		{
+			scenario: "Tampered session is rejected",
+			cookie:   "not-a-sealed-session",
+			status:   http.StatusUnauthorized,
+		},
+		{
+			scenario: "Missing session is rejected",
+			status:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.Handle("/sso/jwks/", jwksTestHandler(key))
+			mux.HandleFunc("/user_management/authenticate", func(w http.ResponseWriter, r *http.Request) {
+				var payload map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&payload)
+
+				if payload["grant_type"] != "refresh_token" || payload["refresh_token"] != "refresh_token_123" {
+					http.Error(w, "invalid refresh token", http.StatusBadRequest)
+					return
+				}
+
+				json.NewEncoder(w).Encode(AuthenticateResponse{
+					User:         user,
+					AccessToken:  refreshedToken,
+					RefreshToken: "refresh_token_456",
+				})
+			})
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := NewClient("test")
+			client.Endpoint = server.URL
+			client.HTTPClient = server.Client()
+
+			var authenticated User
+			handler := SessionMiddleware(client, "client_123", testCookiePassword)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authenticated, _ = UserFromContext(r.Context())
+					w.WriteHeader(http.StatusOK)
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: test.cookie})
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, test.status, rec.Code)
+
+			if test.status != http.StatusOK {
+				require.Empty(t, authenticated)
+				return
+			}
+			require.Equal(t, user, authenticated)
+
+			cookies := rec.Result().Cookies()
+			if !test.refreshedCookie {
+				require.Empty(t, cookies)
+				return
+			}
+
+			require.Len(t, cookies, 1)
+			require.Equal(t, SessionCookieName, cookies[0].Name)
+
+			session, err := UnsealSession(cookies[0].Value, testCookiePassword)
+			require.NoError(t, err)
+			require.Equal(t, refreshedToken, session.AccessToken)
+			require.Equal(t, "refresh_token_456", session.RefreshToken)
+		})
+	}
+}
+
+func TestEnsureValidSession(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	validToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	expiredToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	})
+	refreshedToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"sid": "refreshed",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	user := User{ID: "user_123"}
+
+	mux := http.NewServeMux()
+	mux.Handle("/sso/jwks/", jwksTestHandler(key))
+	mux.HandleFunc("/user_management/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		if payload["refresh_token"] != "refresh_token_123" {
+			http.Error(w, "invalid refresh token", http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(AuthenticateResponse{
+			User:         user,
+			AccessToken:  refreshedToken,
+			RefreshToken: "refresh_token_456",
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	t.Run("Valid session is returned as is", func(t *testing.T) {
+		session := Session{AccessToken: validToken, RefreshToken: "refresh_token_123", User: user}
+
+		ensured, refreshed, err := client.EnsureValidSession(context.Background(), session, "client_123")
+		require.NoError(t, err)
+		require.False(t, refreshed)
+		require.Equal(t, session, ensured)
+	})
+
+	t.Run("Expired session is refreshed", func(t *testing.T) {
+		session := Session{AccessToken: expiredToken, RefreshToken: "refresh_token_123", User: user}
+
+		ensured, refreshed, err := client.EnsureValidSession(context.Background(), session, "client_123")
+		require.NoError(t, err)
+		require.True(t, refreshed)
+		require.Equal(t, refreshedToken, ensured.AccessToken)
+		require.Equal(t, "refresh_token_456", ensured.RefreshToken)
+		require.Equal(t, user, ensured.User)
+	})
+
+	t.Run("Expired session with a revoked refresh token is unrecoverable", func(t *testing.T) {
+		session := Session{AccessToken: expiredToken, RefreshToken: "revoked_refresh_token", User: user}
+
+		_, refreshed, err := client.EnsureValidSession(context.Background(), session, "client_123")
+		require.Error(t, err)
+		require.False(t, refreshed)
+	})
+
+	t.Run("Session of another user is rejected", func(t *testing.T) {
+		session := Session{AccessToken: validToken, RefreshToken: "refresh_token_123", User: User{ID: "user_456"}}
+
+		_, refreshed, err := client.EnsureValidSession(context.Background(), session, "client_123")
+		require.Equal(t, ErrInvalidSession, err)
+		require.False(t, refreshed)
+	})
+
+	t.Run("Expired session of another user is not refreshed", func(t *testing.T) {
+		session := Session{AccessToken: expiredToken, RefreshToken: "refresh_token_123", User: User{ID: "user_456"}}
+
+		_, refreshed, err := client.EnsureValidSession(context.Background(), session, "client_123")
+		require.Equal(t, ErrInvalidSession, err)
+		require.False(t, refreshed)
+	})
+
+	t.Run("Session with an invalid access token is unrecoverable", func(t *testing.T) {
+		session := Session{AccessToken: "not-a-token", RefreshToken: "refresh_token_123", User: user}
+
+		_, refreshed, err := client.EnsureValidSession(context.Background(), session, "client_123")
+		require.Equal(t, ErrInvalidAccessToken, err)
+		require.False(t, refreshed)
+	})
+}
+
+func TestSessionRememberOrganization(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	expiredToken := key.sign(t, map[string]interface{}{
+		"sub": "user_123",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	})
+	refreshedToken := key.sign(t, map[string]interface{}{
+		"sub":    "user_123",
+		"org_id": "org_456",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	})
+
+	var organizationIDs []interface{}
+
+	mux := http.NewServeMux()
+	mux.Handle("/sso/jwks/", jwksTestHandler(key))
+	mux.HandleFunc("/user_management/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		organizationIDs = append(organizationIDs, payload["organization_id"])
+
+		json.NewEncoder(w).Encode(AuthenticateResponse{
+			User:           User{ID: "user_123"},
+			OrganizationID: "org_456",
+			AccessToken:    refreshedToken,
+			RefreshToken:   "refresh_token_456",
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient("test")
+	client.Endpoint = server.URL
+	client.HTTPClient = server.Client()
+
+	session := Session{
+		AccessToken:    expiredToken,
+		RefreshToken:   "refresh_token_123",
+		User:           User{ID: "user_123"},
+		OrganizationID: "org_456",
+	}.RememberOrganization("org_456")
+
+	sealed, err := SealSession(session, testCookiePassword)
+	require.NoError(t, err)
+
+	unsealed, err := UnsealSession(sealed, testCookiePassword)
+	require.NoError(t, err)
+	require.Equal(t, "org_456", DefaultOrganizationID(unsealed))
+
+	ensured, refreshed, err := client.EnsureValidSession(context.Background(), unsealed, "client_123")
+	require.NoError(t, err)
+	require.True(t, refreshed)
+	require.Equal(t, []interface{}{"org_456"}, organizationIDs)
+	require.Equal(t, "org_456", ensured.OrganizationID)
+	require.Equal(t, "org_456", DefaultOrganizationID(ensured))
+
+	// Sessions without a remembered Organization are refreshed unscoped.
+	organizationIDs = nil
+	session.DefaultOrganizationID = ""
+
+	_, _, err = client.EnsureValidSession(context.Background(), session, "client_123")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{nil}, organizationIDs)
+}
+
+func TestUserFromContext(t *testing.T) {
+	_, ok := UserFromContext(context.Background())
+	require.False(t, ok)
+
+	_, ok = SessionFromContext(context.Background())
+	require.False(t, ok)
+
+	user := User{ID: "user_123"}
+	session := Session{AccessToken: "access_token_123", User: user}
+
+	ctx := context.WithValue(context.Background(), userContextKey, user)
+	ctx = context.WithValue(ctx, sessionContextKey, session)
+
+	injectedUser, ok := UserFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, user, injectedUser)
+
+	injectedSession, ok := SessionFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, session, injectedSession)
+}
+
+func sealTestSession(t *testing.T, session Session) string {
+	sealed, err := SealSession(session, testCookiePassword)
+	require.NoError(t, err)
+	return sealed
+}
@@ -5,6 +5,10 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
+
+	"github.com/workos/workos-go/v3/pkg/common"
 )
 
 var (
@@ -27,11 +31,95 @@ type Client struct {
 
 	// The endpoint to WorkOS API.
 	//
-	// Defaults to https://api.workos.com.
+	// Defaults to the WORKOS_API_ENDPOINT environment variable when set, and to
+	// https://api.workos.com otherwise.
 	Endpoint string
 
 	// The function used to encode in JSON. Defaults to json.Marshal.
 	JSONEncode func(v interface{}) ([]byte, error)
+
+	// The redirect URIs that GetAuthorizationURL accepts. When set, a
+	// RedirectURI that is not part of the list is rejected.
+	//
+	// OPTIONAL.
+	AllowedRedirectURIs []string
+
+	// A function invoked with a copy of every request just before it is sent.
+	// The copy's body can be read without affecting the request sent to WorkOS,
+	// which makes it convenient to inspect payloads in tests.
+	//
+	// OPTIONAL.
+	OnRequest func(*http.Request)
+
+	// Additional headers sent with every request, eg. to force specific
+	// behaviors in a sandbox environment. They never override the headers set
+	// by the client itself, such as Authorization or User-Agent.
+	//
+	// OPTIONAL.
+	DefaultHeaders map[string]string
+
+	// The minimum duration between two SendMagicAuthCode calls for the same
+	// email. Calls made within that window return ErrMagicAuthThrottled
+	// without reaching WorkOS. Disabled when zero.
+	//
+	// OPTIONAL.
+	MagicAuthThrottle time.Duration
+
+	// The WorkOS API version requests are pinned to, sent in the
+	// WorkOS-Version header. Defaults to the version of the WorkOS account.
+	//
+	// OPTIONAL.
+	APIVersion string
+
+	// A function called with every failed request, see common.ErrorHandler.
+	//
+	// OPTIONAL.
+	ErrorHandler common.ErrorHandler
+
+	// A function called after every request, see common.RequestCompleteFunc.
+	//
+	// OPTIONAL.
+	OnRequestComplete common.RequestCompleteFunc
+
+	// The maximum size of a response body, in bytes. Reading a larger body
+	// fails with workos_errors.ErrResponseTooLarge. Defaults to 32 MiB.
+	//
+	// OPTIONAL.
+	MaxResponseBytes int64
+
+	// How long the JSON Web Key Set fetched by VerifyAccessToken is cached.
+	// The set is cached until RefreshJWKS is called when zero.
+	//
+	// OPTIONAL.
+	JWKSCacheTTL time.Duration
+
+	// The minimum duration between two fetches of the JSON Web Key Set of a
+	// client triggered by tokens signed with an unknown key, so that tokens
+	// with made up key identifiers cannot flood WorkOS with requests. Defaults
+	// to DefaultJWKSMinRefreshInterval.
+	//
+	// OPTIONAL.
+	JWKSMinRefreshInterval time.Duration
+
+	// A cache storing the JSON Web Key Sets fetched by VerifyAccessToken, eg.
+	// to share them between Clients. Defaults to an in-memory cache specific
+	// to the Client.
+	//
+	// OPTIONAL.
+	JWKSCache JWKSCache
+
+	// The function used to determine the current time, eg. to compute
+	// AuthenticateResponse.AccessTokenExpiresAt. Defaults to time.Now.
+	//
+	// OPTIONAL.
+	Now func() time.Time
+
+	jwksMu     sync.Mutex
+	jwks       map[string]cachedJWKS
+	sharedJWKS map[string]cachedJWKS
+
+	magicAuthMu     sync.Mutex
+	magicAuthSentAt map[string]time.Time
 }
 
 // SetAPIKey configures the default client that is used by the User management methods
@@ -48,6 +136,14 @@ func GetUser(
 	return DefaultClient.GetUser(ctx, opts)
 }
 
+// GetUsers gets the Users with the given IDs.
+func GetUsers(
+	ctx context.Context,
+	ids []string,
+) (map[string]User, []error) {
+	return DefaultClient.GetUsers(ctx, ids)
+}
+
 // ListUsers gets a list of Users.
 func ListUsers(
 	ctx context.Context,
@@ -56,6 +152,30 @@ func ListUsers(
 	return DefaultClient.ListUsers(ctx, opts)
 }
 
+// GetUserByEmail gets the User with the given email.
+func GetUserByEmail(
+	ctx context.Context,
+	email string,
+) (User, error) {
+	return DefaultClient.GetUserByEmail(ctx, email)
+}
+
+// IsEmailAvailable reports whether no User has the given email yet.
+func IsEmailAvailable(
+	ctx context.Context,
+	email string,
+) (bool, error) {
+	return DefaultClient.IsEmailAvailable(ctx, email)
+}
+
+// IncrementalUserSync gets the Users that were updated after the given time.
+func IncrementalUserSync(
+	ctx context.Context,
+	since time.Time,
+) ([]User, error) {
+	return DefaultClient.IncrementalUserSync(ctx, since)
+}
+
 // CreateUser creates a User.
 func CreateUser(
 	ctx context.Context,
@@ -80,12 +200,70 @@ func DeleteUser(
 	return DefaultClient.DeleteUser(ctx, opts)
 }
 
+// DeleteUserWithMemberships deletes the OrganizationMemberships of a User, then
+// the User.
+func DeleteUserWithMemberships(
+	ctx context.Context,
+	opts DeleteUserOpts,
+) ([]OrganizationMembership, error) {
+	return DefaultClient.DeleteUserWithMemberships(ctx, opts)
+}
+
 // GetAuthorizationURL returns an authorization url generated with the given
 // options.
 func GetAuthorizationURL(opts GetAuthorizationURLOpts) (*url.URL, error) {
 	return DefaultClient.GetAuthorizationURL(opts)
 }
 
+// GetLogoutURL returns a logout url generated with the given options.
+func GetLogoutURL(opts GetLogoutURLOpts) (*url.URL, error) {
+	return DefaultClient.GetLogoutURL(opts)
+}
+
+// GetJWKSURL returns the URL of the JSON Web Key Set used to sign the access
+// tokens issued for the given client.
+func GetJWKSURL(clientID string) (*url.URL, error) {
+	return DefaultClient.GetJWKSURL(clientID)
+}
+
+// VerifyAccessToken verifies an access token issued by WorkOS for the given
+// client and returns its claims.
+func VerifyAccessToken(
+	ctx context.Context,
+	clientID string,
+	accessToken string,
+) (AccessTokenClaims, error) {
+	return DefaultClient.VerifyAccessToken(ctx, clientID, accessToken)
+}
+
+// GetUserFromToken verifies an access token issued by WorkOS for the given
+// client and gets the User it was issued to.
+func GetUserFromToken(
+	ctx context.Context,
+	clientID string,
+	accessToken string,
+) (User, error) {
+	return DefaultClient.GetUserFromToken(ctx, clientID, accessToken)
+}
+
+// RefreshJWKS fetches the JSON Web Key Set of the given client again, replacing
+// the cached one.
+func RefreshJWKS(
+	ctx context.Context,
+	clientID string,
+) error {
+	return DefaultClient.RefreshJWKS(ctx, clientID)
+}
+
+// GetJWKSJSON returns the raw JSON Web Key Set used to sign the access tokens
+// issued for the given client.
+func GetJWKSJSON(
+	ctx context.Context,
+	clientID string,
+) ([]byte, error) {
+	return DefaultClient.GetJWKSJSON(ctx, clientID)
+}
+
 // AuthenticateWithPassword authenticates a user with email and password and optionally creates a session.
 func AuthenticateWithPassword(
 	ctx context.Context,
@@ -136,6 +314,15 @@ func AuthenticateWithOrganizationSelection(
 	return DefaultClient.AuthenticateWithOrganizationSelection(ctx, opts)
 }
 
+// AuthenticateWithRefreshToken exchanges a refresh token for a new access token
+// and refresh token.
+func AuthenticateWithRefreshToken(
+	ctx context.Context,
+	opts AuthenticateWithRefreshTokenOpts,
+) (AuthenticateResponse, error) {
+	return DefaultClient.AuthenticateWithRefreshToken(ctx, opts)
+}
+
 // SendVerificationEmail creates an email verification challenge and emails verification token to user.
 func SendVerificationEmail(
 	ctx context.Context,
@@ -200,6 +387,15 @@ func GetOrganizationMembership(
 	return DefaultClient.GetOrganizationMembership(ctx, opts)
 }
 
+// ListOrganizationRoles gets the Roles that can be assigned to the members of
+// an Organization.
+func ListOrganizationRoles(
+	ctx context.Context,
+	opts ListOrganizationRolesOpts,
+) (ListOrganizationRolesResponse, error) {
+	return DefaultClient.ListOrganizationRoles(ctx, opts)
+}
+
 // ListOrganizationMemberships gets a list of OrganizationMemberhips.
 func ListOrganizationMemberships(
 	ctx context.Context,
@@ -208,6 +404,53 @@ func ListOrganizationMemberships(
 	return DefaultClient.ListOrganizationMemberships(ctx, opts)
 }
 
+// ListOrganizationMembershipsByUser gets the OrganizationMemberships of each
+// of the given Users, grouped by User ID.
+func ListOrganizationMembershipsByUser(
+	ctx context.Context,
+	userIDs []string,
+	opts ListOrganizationMembershipsOpts,
+) (map[string][]OrganizationMembership, error) {
+	return DefaultClient.ListOrganizationMembershipsByUser(ctx, userIDs, opts)
+}
+
+// ListOrganizationMembersWithRoles gets every Organization Membership of an
+// Organization along with the Role of each member.
+func ListOrganizationMembersWithRoles(
+	ctx context.Context,
+	organizationID string,
+) ([]MembershipWithRole, error) {
+	return DefaultClient.ListOrganizationMembersWithRoles(ctx, organizationID)
+}
+
+// IsActiveMember reports whether a User has an active Organization Membership
+// in an Organization.
+func IsActiveMember(
+	ctx context.Context,
+	userID string,
+	organizationID string,
+) (bool, error) {
+	return DefaultClient.IsActiveMember(ctx, userID, organizationID)
+}
+
+// ListUserOrganizations gets the Organizations a User is an active member of.
+func ListUserOrganizations(
+	ctx context.Context,
+	userID string,
+) ([]OrganizationSummary, error) {
+	return DefaultClient.ListUserOrganizations(ctx, userID)
+}
+
+// ListUserOrganizationsWithOpts gets the Organizations a User is a member of,
+// with the given options.
+func ListUserOrganizationsWithOpts(
+	ctx context.Context,
+	userID string,
+	opts ListUserOrganizationsOpts,
+) ([]OrganizationSummary, error) {
+	return DefaultClient.ListUserOrganizationsWithOpts(ctx, userID, opts)
+}
+
 // CreateOrganizationMembership creates a OrganizationMembership.
 func CreateOrganizationMembership(
 	ctx context.Context,
@@ -216,6 +459,22 @@ func CreateOrganizationMembership(
 	return DefaultClient.CreateOrganizationMembership(ctx, opts)
 }
 
+// CreateOrganizationMemberships creates the given OrganizationMemberships.
+func CreateOrganizationMemberships(
+	ctx context.Context,
+	opts []CreateOrganizationMembershipOpts,
+) BulkResult {
+	return DefaultClient.CreateOrganizationMemberships(ctx, opts)
+}
+
+// UpdateOrganizationMembership updates the role of an OrganizationMembership.
+func UpdateOrganizationMembership(
+	ctx context.Context,
+	opts UpdateOrganizationMembershipOpts,
+) (OrganizationMembership, error) {
+	return DefaultClient.UpdateOrganizationMembership(ctx, opts)
+}
+
 // DeleteOrganizationMembership deletes a existing OrganizationMembership.
 func DeleteOrganizationMembership(
 	ctx context.Context,
@@ -231,6 +490,20 @@ func GetInvitation(
 	return DefaultClient.GetInvitation(ctx, opts)
 }
 
+// FindInvitationByToken fetches an Invitation by its token.
+func FindInvitationByToken(
+	ctx context.Context,
+	opts FindInvitationByTokenOpts,
+) (Invitation, error) {
+	return DefaultClient.FindInvitationByToken(ctx, opts)
+}
+
+// InvitationFromRequest fetches the Invitation whose token is carried by the
+// invitation_token query parameter of the request.
+func InvitationFromRequest(r *http.Request) (Invitation, error) {
+	return DefaultClient.InvitationFromRequest(r)
+}
+
 func ListInvitations(
 	ctx context.Context,
 	opts ListInvitationsOpts,
@@ -238,6 +511,15 @@ func ListInvitations(
 	return DefaultClient.ListInvitations(ctx, opts)
 }
 
+// ListPendingInvitations gets all the Invitations of an Organization that are
+// still pending, going through every page of results.
+func ListPendingInvitations(
+	ctx context.Context,
+	organizationID string,
+) ([]Invitation, error) {
+	return DefaultClient.ListPendingInvitations(ctx, organizationID)
+}
+
 func SendInvitation(
 	ctx context.Context,
 	opts SendInvitationOpts,
@@ -245,9 +527,51 @@ func SendInvitation(
 	return DefaultClient.SendInvitation(ctx, opts)
 }
 
+// SendInvitations sends the given Invitations.
+func SendInvitations(
+	ctx context.Context,
+	opts []SendInvitationOpts,
+) BulkResult {
+	return DefaultClient.SendInvitations(ctx, opts)
+}
+
 func RevokeInvitation(
 	ctx context.Context,
 	opts RevokeInvitationOpts,
 ) (Invitation, error) {
 	return DefaultClient.RevokeInvitation(ctx, opts)
 }
+
+// RevokeInvitations revokes the Invitations with the given IDs.
+func RevokeInvitations(
+	ctx context.Context,
+	ids []string,
+) BulkResult {
+	return DefaultClient.RevokeInvitations(ctx, ids)
+}
+
+// ResendInvitation sends the email of a pending Invitation again.
+func ResendInvitation(
+	ctx context.Context,
+	opts ResendInvitationOpts,
+) (Invitation, error) {
+	return DefaultClient.ResendInvitation(ctx, opts)
+}
+
+// AcceptInvitation accepts an Invitation on behalf of the invited User.
+func AcceptInvitation(
+	ctx context.Context,
+	opts AcceptInvitationOpts,
+) (Invitation, error) {
+	return DefaultClient.AcceptInvitation(ctx, opts)
+}
+
+// EnsureValidSession verifies the access token of a session and refreshes the
+// session when the token is expired.
+func EnsureValidSession(
+	ctx context.Context,
+	session Session,
+	clientID string,
+) (Session, bool, error) {
+	return DefaultClient.EnsureValidSession(ctx, session, clientID)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
+	"github.com/workos/workos-go/v3/internal/workos"
 	"github.com/workos/workos-go/v3/pkg/common"
 	"github.com/workos/workos-go/v3/pkg/mfa"
 
@@ -45,6 +47,20 @@ func TestUserManagementGetUser(t *testing.T) {
 	require.Equal(t, expectedResponse, userRes)
 }
 
+func TestUserManagementGetUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getUsersTestHandler))
+	defer server.Close()
+
+	DefaultClient = mockClient(server)
+
+	SetAPIKey("test")
+
+	users, errs := GetUsers(context.Background(), []string{"user_123", "user_456"})
+
+	require.Empty(t, errs)
+	require.Len(t, users, 2)
+}
+
 func TestUserManagementListUsers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(listUsersTestHandler))
 
@@ -125,11 +141,12 @@ func TestUserManagementUpdateUser(t *testing.T) {
 		UpdatedAt:     "2021-06-25T19:07:33.155Z",
 	}
 
+	emailVerified := true
 	userRes, err := UpdateUser(context.Background(), UpdateUserOpts{
 		User:          "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
 		FirstName:     "Marcelina",
 		LastName:      "Davis",
-		EmailVerified: true,
+		EmailVerified: &emailVerified,
 		Password:      "pass",
 	})
 
@@ -155,11 +172,12 @@ func TestUserManagementUpdateUserPasswordHash(t *testing.T) {
 		UpdatedAt:     "2021-06-25T19:07:33.155Z",
 	}
 
+	emailVerified := true
 	userRes, err := UpdateUser(context.Background(), UpdateUserOpts{
 		User:             "user_01E3JC5F5Z1YJNPGVYWV9SX6GH",
 		FirstName:        "Marcelina",
 		LastName:         "Davis",
-		EmailVerified:    true,
+		EmailVerified:    &emailVerified,
 		PasswordHash:     "$2b$10$dXS6RadWKYIqs6vOwqKZceLuCIqz6S81t06.yOkGJbbfeO9go4fai",
 		PasswordHashType: "bcrypt",
 	})
@@ -275,7 +293,8 @@ func TestUserManagementResetPassword(t *testing.T) {
 	}
 
 	userRes, err := ResetPassword(context.Background(), ResetPasswordOpts{
-		Token: "testToken",
+		Token:       "testToken",
+		NewPassword: "new_password",
 	})
 
 	require.NoError(t, err)
@@ -443,7 +462,11 @@ func TestUserManagementAuthenticateWithOrganizationSelection(t *testing.T) {
 		OrganizationID: "org_123",
 	}
 
-	authenticationRes, err := AuthenticateWithOrganizationSelection(context.Background(), AuthenticateWithOrganizationSelectionOpts{})
+	authenticationRes, err := AuthenticateWithOrganizationSelection(context.Background(), AuthenticateWithOrganizationSelectionOpts{
+		ClientID:                   "project_123",
+		PendingAuthenticationToken: "cTDQJTTkTkkVYxQUlKBIxEsFs",
+		OrganizationID:             "org_123",
+	})
 
 	require.NoError(t, err)
 	require.Equal(t, expectedResponse, authenticationRes)
@@ -543,6 +566,7 @@ func TestUserManagementGetOrganizationMembership(t *testing.T) {
 		ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
 		UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
 		OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
+		Role:           RoleResponse{Slug: "admin"},
 		CreatedAt:      "2021-06-25T19:07:33.155Z",
 		UpdatedAt:      "2021-06-25T19:07:33.155Z",
 	}
@@ -597,8 +621,11 @@ func TestUserManagementCreateOrganizationMembership(t *testing.T) {
 		ID:             "om_01E4ZCR3C56J083X43JQXF3JK5",
 		UserID:         "user_01E4ZCR3C5A4QZ2Z2JQXGKZJ9E",
 		OrganizationID: "org_01E4ZCR3C56J083X43JQXF3JK5",
-		CreatedAt:      "2021-06-25T19:07:33.155Z",
-		UpdatedAt:      "2021-06-25T19:07:33.155Z",
+		Role: RoleResponse{
+			Slug: "member",
+		},
+		CreatedAt: "2021-06-25T19:07:33.155Z",
+		UpdatedAt: "2021-06-25T19:07:33.155Z",
 	}
 
 	userRes, err := CreateOrganizationMembership(context.Background(), CreateOrganizationMembershipOpts{
@@ -740,3 +767,66 @@ func TestUsersRevokeInvitation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedResponse, revokeRes)
 }
+
+func TestUserManagementVerifyAccessToken(t *testing.T) {
+	key := newTestSigningKey(t, "key_123")
+
+	server := httptest.NewServer(jwksTestHandler(key))
+	defer server.Close()
+
+	DefaultClient = mockClient(server)
+
+	SetAPIKey("test")
+
+	accessToken := key.sign(t, map[string]interface{}{
+		"sub":         "user_123",
+		"permissions": []string{"posts:read"},
+	})
+
+	claims, err := VerifyAccessToken(context.Background(), "client_123", accessToken)
+
+	require.NoError(t, err)
+	require.Equal(t, AccessTokenClaims{
+		Subject:     "user_123",
+		Permissions: []string{"posts:read"},
+	}, claims)
+}
+
+func TestUserManagementEndpointFromEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(getUserTestHandler))
+	defer server.Close()
+
+	// The clients are created before the variable is set.
+	DefaultClient = NewClient("test")
+	DefaultClient.HTTPClient = server.Client()
+	literal := &Client{}
+
+	os.Setenv(workos.EndpointEnvVar, server.URL)
+	defer os.Unsetenv(workos.EndpointEnvVar)
+
+	user, err := GetUser(context.Background(), GetUserOpts{
+		User: "user_123",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "user_01E3JC5F5Z1YJNPGVYWV9SX6GH", user.ID)
+
+	u, err := literal.GetJWKSURL("client_123")
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/sso/jwks/client_123", u.String())
+
+	// An explicit Endpoint takes precedence.
+	literal.Endpoint = "https://sandbox.workos.test"
+	u, err = literal.GetJWKSURL("client_123")
+	require.NoError(t, err)
+	require.Equal(t, "https://sandbox.workos.test/sso/jwks/client_123", u.String())
+
+	os.Unsetenv(workos.EndpointEnvVar)
+	u, err = literal.GetJWKSURL("client_123")
+	require.NoError(t, err)
+	require.Equal(t, "https://sandbox.workos.test/sso/jwks/client_123", u.String())
+
+	literal.Endpoint = ""
+	u, err = literal.GetJWKSURL("client_123")
+	require.NoError(t, err)
+	require.Equal(t, workos.ProductionEndpoint+"/sso/jwks/client_123", u.String())
+}
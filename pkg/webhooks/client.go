@@ -1,13 +1,22 @@
 package webhooks
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/workos/workos-go/v3/pkg/events"
 )
 
 // This represents the list of errors that could be raised when using the webhook package.
@@ -17,18 +26,84 @@ var (
 	ErrNotSigned        = errors.New("webhook has no WorkOS header")
 	ErrInvalidTimestamp = errors.New("webhook has an invalid timestamp")
 	ErrOutsideTolerance = errors.New("webhook has a timestamp that is out of tolerance")
+	ErrMissingSecret    = errors.New("webhook secret is not set, set the " + SecretEnvVar + " environment variable")
+	ErrBodyTooLarge     = errors.New("webhook body exceeds the maximum size")
+	ErrInvalidBody      = errors.New("webhook has an invalid body")
 )
 
+// SecretEnvVar is the environment variable NewClientFromEnv reads the webhook
+// secret from.
+const SecretEnvVar = "WORKOS_WEBHOOK_SECRET"
+
+// DefaultMaxBodyBytes is the maximum size of a webhook body, in bytes, read by
+// RawBodyFromRequest and by Handler when the client does not configure one
+// with SetMaxBodyBytes.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
 // The Client used to interact with Webhooks.
 type Client struct {
-	now       func() time.Time
-	tolerance time.Duration
-	secret    string
+	now          func() time.Time
+	tolerance    time.Duration
+	secrets      []string
+	maxBodyBytes int64
 }
 
 // Constructs a new Client.
 func NewClient(secret string) *Client {
-	return &Client{now: time.Now, tolerance: 180 * time.Second, secret: secret}
+	return &Client{now: time.Now, tolerance: 180 * time.Second, secrets: []string{secret}}
+}
+
+// Constructs a new Client accepting webhooks signed with any of the given
+// secrets, eg. when the webhooks of several WorkOS environments are received
+// by the same endpoint. ValidatePayload returns ErrNoValidSignature when none
+// of the secrets matches.
+//
+// It returns ErrMissingSecret when secrets is empty or contains an empty
+// secret, since anyone could sign webhooks with an empty key.
+func NewMultiClient(secrets []string, tolerance time.Duration) (*Client, error) {
+	if len(secrets) == 0 {
+		return nil, ErrMissingSecret
+	}
+	for _, secret := range secrets {
+		if secret == "" {
+			return nil, ErrMissingSecret
+		}
+	}
+
+	return &Client{now: time.Now, tolerance: tolerance, secrets: append([]string(nil), secrets...)}, nil
+}
+
+// Constructs a new Client with the secret read from the WORKOS_WEBHOOK_SECRET
+// environment variable and the default tolerance. It returns ErrMissingSecret
+// when the variable is not set.
+func NewClientFromEnv() (*Client, error) {
+	secret := os.Getenv(SecretEnvVar)
+	if secret == "" {
+		return nil, ErrMissingSecret
+	}
+	return NewClient(secret), nil
+}
+
+// WithAdditionalSecret returns a copy of the client also accepting webhooks
+// signed with secret, leaving c unchanged.
+//
+// It is meant for secret rotations: during the rotation window, validate
+// webhooks with c.WithAdditionalSecret(newSecret) so that both the old and the
+// new secret are accepted, then switch to NewClient(newSecret) once WorkOS only
+// signs with the new one.
+//
+// It returns ErrMissingSecret when secret is empty, eg. when the new secret is
+// read from an environment variable that is not set.
+func (c *Client) WithAdditionalSecret(secret string) (*Client, error) {
+	if secret == "" {
+		return nil, ErrMissingSecret
+	}
+
+	secrets := make([]string, 0, len(c.secrets)+1)
+	secrets = append(secrets, c.secrets...)
+	secrets = append(secrets, secret)
+
+	return &Client{now: c.now, tolerance: c.tolerance, secrets: secrets, maxBodyBytes: c.maxBodyBytes}, nil
 }
 
 // Sets the function used to determine the current time. Usually you'll only
@@ -43,6 +118,51 @@ func (c *Client) SetTolerance(tolerance time.Duration) {
 	c.tolerance = tolerance
 }
 
+// Sets the maximum size of the webhook bodies read by Handler, in bytes.
+// Larger bodies are rejected with a 413 status before their signature is
+// checked. Defaults to DefaultMaxBodyBytes.
+func (c *Client) SetMaxBodyBytes(maxBodyBytes int64) {
+	c.maxBodyBytes = maxBodyBytes
+}
+
+func (c *Client) maxBody() int64 {
+	if c.maxBodyBytes > 0 {
+		return c.maxBodyBytes
+	}
+	return DefaultMaxBodyBytes
+}
+
+// readBody reads and closes the body of a webhook request, returning
+// ErrBodyTooLarge when it is larger than maxBytes. w can be nil when the
+// request is not being answered.
+func readBody(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	r.Body.Close()
+	if err != nil {
+		// http.MaxBytesReader cuts larger bodies at maxBytes.
+		if int64(len(body)) == maxBytes {
+			return nil, ErrBodyTooLarge
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+// ValidateOption configures a single ValidatePayload call.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	tolerance time.Duration
+}
+
+// WithTolerance overrides the client's tolerance for a single ValidatePayload
+// call, eg. for events that are known to be delivered in batches.
+func WithTolerance(tolerance time.Duration) ValidateOption {
+	return func(o *validateOptions) {
+		o.tolerance = tolerance
+	}
+}
+
 type signedHeader struct {
 	timestamp string
 	signature string
@@ -60,12 +180,17 @@ func parseSignatureHeader(header string) (*signedHeader, error) {
 		return signedHeader, ErrInvalidHeader
 	}
 
+	timestampPart := strings.TrimSpace(signatureParts[0])
+	signaturePart := strings.TrimSpace(signatureParts[1])
+	if !strings.HasPrefix(timestampPart, "t=") || !strings.HasPrefix(signaturePart, "v1=") {
+		return signedHeader, ErrInvalidHeader
+	}
+
 	// Turn the timestamp into Unix time
-	rawTimestamp := signatureParts[0][2:len(signatureParts[0])]
-	signedHeader.timestamp = rawTimestamp
+	signedHeader.timestamp = strings.TrimPrefix(timestampPart, "t=")
 
 	// Create the signature and check that it exists
-	signedHeader.signature = signatureParts[1][4:len(signatureParts[1])]
+	signedHeader.signature = strings.TrimPrefix(signaturePart, "v1=")
 	if len(signedHeader.signature) == 0 {
 		return signedHeader, ErrNoValidSignature
 	}
@@ -73,46 +198,72 @@ func parseSignatureHeader(header string) (*signedHeader, error) {
 	return signedHeader, nil
 }
 
-func (c *Client) checkTimestamp(timestamp string) error {
+func (c *Client) checkTimestamp(timestamp string, tolerance time.Duration) error {
 	intTimestamp, err := strconv.ParseInt(timestamp, 10, 64)
 	if err != nil {
 		return ErrInvalidHeader
 	}
 
-	formattedTime := time.Unix(intTimestamp/1000, 0)
-	currentTime := c.now().Round(0)
+	// The timestamp is in milliseconds, keep its sub-second precision and
+	// compare both times in UTC.
+	formattedTime := time.Unix(0, intTimestamp*int64(time.Millisecond)).UTC()
+	currentTime := c.now().Round(0).UTC()
 
 	diff := currentTime.Sub(formattedTime)
 
-	if diff < c.tolerance {
-		return nil
-	} else {
+	if diff >= tolerance {
 		return ErrInvalidTimestamp
 	}
+	// A timestamp in the future is as suspicious as an old one: it would let
+	// a captured webhook be replayed for longer than the tolerance.
+	if -diff >= tolerance {
+		return ErrOutsideTolerance
+	}
+	return nil
 }
 
 func (c *Client) checkSignature(bodyString string, rawTimestamp string, signature string) error {
-	unhashedDigest := rawTimestamp + "." + bodyString
-	hash := hmac.New(sha256.New, []byte(c.secret))
+	for _, secret := range c.secrets {
+		// Anyone can sign a webhook with an empty key, eg. a client built with
+		// NewClient(os.Getenv(...)) when the variable is unset.
+		if secret == "" {
+			continue
+		}
 
-	hash.Write([]byte(unhashedDigest))
+		expected := computeSignature(secret, rawTimestamp, bodyString)
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
 
-	digest := hex.EncodeToString(hash.Sum(nil))
+	return ErrNoValidSignature
+}
 
-	if signature == digest {
-		return nil
-	} else {
-		return ErrNoValidSignature
-	}
+// computeSignature returns the hex encoded HMAC SHA256 signature of a webhook
+// body sent at the given timestamp, in milliseconds.
+func computeSignature(secret string, rawTimestamp string, bodyString string) string {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte(rawTimestamp + "." + bodyString))
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
-func (c *Client) ValidatePayload(workosHeader string, bodyString string) (string, error) {
+// ValidatePayload validates the WorkOS-Signature header of a webhook against
+// its raw body and returns the body when the signature is valid and recent
+// enough. It returns ErrInvalidTimestamp when the webhook is older than the
+// tolerance, and ErrOutsideTolerance when its timestamp is further than the
+// tolerance in the future.
+func (c *Client) ValidatePayload(workosHeader string, bodyString string, opts ...ValidateOption) (string, error) {
+	o := validateOptions{tolerance: c.tolerance}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	header, err := parseSignatureHeader(workosHeader)
 	if err != nil {
 		return "", err
 	}
 
-	if err := c.checkTimestamp(header.timestamp); err != nil {
+	if err := c.checkTimestamp(header.timestamp, o.tolerance); err != nil {
 		return "", err
 	}
 
@@ -122,3 +273,165 @@ func (c *Client) ValidatePayload(workosHeader string, bodyString string) (string
 
 	return bodyString, nil
 }
+
+// VerifyFromHeaderAndBody validates the WorkOS-Signature header of a webhook
+// against its raw body with the given secret and tolerance, and returns the
+// decoded event. It is meant to be the single call needed to receive webhooks,
+// whatever the HTTP framework in use:
+//
+//	body, err := webhooks.RawBodyFromRequest(r)
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	event, err := webhooks.VerifyFromHeaderAndBody(
+//	    r.Header.Get(webhooks.SignatureHeader),
+//	    body,
+//	    secret,
+//	    3*time.Minute,
+//	)
+//	if err != nil {
+//	    // Reject the webhook.
+//	}
+//
+// It returns ErrMissingSecret when secret is empty, the errors of
+// ValidatePayload when the header is missing, malformed, outside the
+// tolerance or not signed with secret, and ErrInvalidBody when the body is not a JSON event.
+func VerifyFromHeaderAndBody(header string, body []byte, secret string, tolerance time.Duration) (events.Event, error) {
+	if secret == "" {
+		return events.Event{}, ErrMissingSecret
+	}
+
+	client, err := NewMultiClient([]string{secret}, tolerance)
+	if err != nil {
+		return events.Event{}, err
+	}
+	if _, err := client.ValidatePayload(header, string(body)); err != nil {
+		return events.Event{}, err
+	}
+
+	var event events.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return events.Event{}, ErrInvalidBody
+	}
+	return event, nil
+}
+
+// SignatureHeader is the header carrying the signature of a webhook.
+const SignatureHeader = "WorkOS-Signature"
+
+// RawBodyFromRequest reads the raw body of a webhook request, as needed by
+// ValidatePayload, and restores it so that it can be read again afterwards, eg.
+// by a framework parsing the body.
+//
+// It must be called before anything else reads the body: the signature is
+// computed over the exact bytes sent by WorkOS, so validating a body that was
+// parsed and re-encoded fails.
+//
+// Like Handler, it reads at most DefaultMaxBodyBytes and returns
+// ErrBodyTooLarge for larger bodies.
+func RawBodyFromRequest(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return []byte{}, nil
+	}
+
+	body, err := readBody(nil, r, DefaultMaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Handler returns an http.Handler receiving webhooks. The body of each request
+// is fully read and its signature validated before the decoded event is passed
+// to next along with the request context, so that next respects the deadline
+// and cancellation of the request.
+//
+// Requests with an invalid signature or body are answered with a 400 status,
+// bodies larger than the client's maximum body size with a 413 status, and a
+// 500 status is returned when next fails.
+func (c *Client) Handler(next func(ctx context.Context, event events.Event) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(w, r, c.maxBody())
+		if err == ErrBodyTooLarge {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "unreadable webhook body", http.StatusBadRequest)
+			return
+		}
+
+		if _, err = c.ValidatePayload(r.Header.Get(SignatureHeader), string(body)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var event events.Event
+		if err = json.Unmarshal(body, &event); err != nil {
+			http.Error(w, "invalid webhook body", http.StatusBadRequest)
+			return
+		}
+
+		if err = next(r.Context(), event); err != nil {
+			http.Error(w, "webhook could not be processed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// SelfTest signs sampleEvent with secret as WorkOS would, POSTs it to handler
+// and returns an error unless the handler answers with a 2xx status. It is meant
+// to be used in tests to check that an endpoint and its secret are wired up
+// correctly before going live.
+func SelfTest(handler http.Handler, secret string, sampleEvent []byte) error {
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	signature := computeSignature(secret, timestamp, string(sampleEvent))
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/", bytes.NewReader(sampleEvent))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "t="+timestamp+", v1="+signature)
+
+	rec := &selfTestResponseWriter{header: make(http.Header)}
+	handler.ServeHTTP(rec, req)
+
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("webhook self test failed: handler answered with status %d: %s", status, strings.TrimSpace(rec.body.String()))
+	}
+	return nil
+}
+
+// selfTestResponseWriter records the response of the handler tested by
+// SelfTest.
+type selfTestResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *selfTestResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *selfTestResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *selfTestResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
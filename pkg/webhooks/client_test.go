@@ -1,13 +1,21 @@
 package webhooks_test
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"github.com/workos/workos-go/v3/pkg/webhooks"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/workos/workos-go/v3/pkg/events"
+	"github.com/workos/workos-go/v3/pkg/webhooks"
 )
 
 func TestWebhookWithValidHeader(t *testing.T) {
@@ -86,6 +94,48 @@ func TestWebhookWithTimestampOlderThanTolerance(t *testing.T) {
 	}
 }
 
+func TestWebhookWithTimestampInTheFuture(t *testing.T) {
+	tolerance := 180 * time.Second
+	secret := "secret"
+	now := time.Unix(0, 0)
+
+	client := webhooks.NewClient(secret)
+	client.SetNow(func() time.Time { return now })
+
+	body := "{'data': 'foobar'}"
+
+	header := mockWebhookHeader(now.Add(tolerance-time.Second), secret, body)
+	if _, err := client.ValidatePayload(header, body); err != nil {
+		t.Errorf("expected no error within the tolerance, but got '%s'", err)
+	}
+
+	header = mockWebhookHeader(now.Add(24*time.Hour), secret, body)
+	if _, err := client.ValidatePayload(header, body); err != webhooks.ErrOutsideTolerance {
+		t.Errorf("expected a '%s' error, but got a '%v'", webhooks.ErrOutsideTolerance, err)
+	}
+}
+
+func TestWebhookAtMillisecondToleranceBoundary(t *testing.T) {
+	tolerance := 180 * time.Second
+	secret := "secret"
+	signedAt := time.Date(2023, 1, 1, 12, 0, 0, int(500*time.Millisecond), time.FixedZone("UTC-7", -7*60*60))
+
+	client := webhooks.NewClient(secret)
+
+	body := "{'data': 'foobar'}"
+	header := mockWebhookHeader(signedAt, secret, body)
+
+	client.SetNow(func() time.Time { return signedAt.Add(tolerance - time.Millisecond).UTC() })
+	if _, err := client.ValidatePayload(header, body); err != nil {
+		t.Errorf("expected no error one millisecond before the tolerance, but got '%s'", err)
+	}
+
+	client.SetNow(func() time.Time { return signedAt.Add(tolerance) })
+	if _, err := client.ValidatePayload(header, body); err != webhooks.ErrInvalidTimestamp {
+		t.Errorf("expected a '%s' error at the tolerance, but got '%s'", webhooks.ErrInvalidTimestamp, err)
+	}
+}
+
 func TestWebhookWithCustomTolerance(t *testing.T) {
 	tolerance := 240 * time.Second
 	secret := "secret"
@@ -108,6 +158,31 @@ func TestWebhookWithCustomTolerance(t *testing.T) {
 	}
 }
 
+func TestWebhookWithPerCallTolerance(t *testing.T) {
+	secret := "secret"
+	now := time.Unix(0, 0)
+
+	client := webhooks.NewClient(secret)
+	client.SetNow(func() time.Time { return now.Add(600 * time.Second) })
+
+	body := "{'data': 'foobar'}"
+	header := mockWebhookHeader(now, secret, body)
+
+	actual, err := client.ValidatePayload(header, body, webhooks.WithTolerance(15*time.Minute))
+	if err != nil {
+		t.Errorf("expected no error, but got '%s'", err)
+	}
+
+	if actual != body {
+		t.Errorf("expected output to be '%s', but got '%s'", body, actual)
+	}
+
+	_, err = client.ValidatePayload(header, body)
+	if err != webhooks.ErrInvalidTimestamp {
+		t.Errorf("expected a '%s' error, but got a '%s'", webhooks.ErrInvalidTimestamp, err)
+	}
+}
+
 func TestWebhookWithInvalidSignature(t *testing.T) {
 	secret := "secret"
 
@@ -122,8 +197,373 @@ func TestWebhookWithInvalidSignature(t *testing.T) {
 	}
 }
 
+func TestWebhookWithMultipleSecrets(t *testing.T) {
+	client, err := webhooks.NewMultiClient([]string{"staging_secret", "production_secret"}, 180*time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	body := "{'data': 'foobar'}"
+
+	header := mockWebhookHeader(time.Now(), "production_secret", body)
+	if _, err := client.ValidatePayload(header, body); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+
+	header = mockWebhookHeader(time.Now(), "other_secret", body)
+	if _, err := client.ValidatePayload(header, body); err != webhooks.ErrNoValidSignature {
+		t.Errorf("expected a '%s' error, but got a '%s'", webhooks.ErrNoValidSignature, err)
+	}
+}
+
+func TestWebhookSignedWithEmptyKey(t *testing.T) {
+	body := "{'data': 'foobar'}"
+	header := mockWebhookHeader(time.Now(), "", body)
+
+	client := webhooks.NewClient("")
+	if _, err := client.ValidatePayload(header, body); err != webhooks.ErrNoValidSignature {
+		t.Errorf("expected a '%s' error, but got a '%v'", webhooks.ErrNoValidSignature, err)
+	}
+
+	for _, secrets := range [][]string{nil, {""}, {"production_secret", ""}} {
+		if _, err := webhooks.NewMultiClient(secrets, 180*time.Second); err != webhooks.ErrMissingSecret {
+			t.Errorf("expected a '%s' error for %q, but got a '%v'", webhooks.ErrMissingSecret, secrets, err)
+		}
+	}
+}
+
+func TestWebhookWithAdditionalSecret(t *testing.T) {
+	client := webhooks.NewClient("old_secret")
+	rotating, err := client.WithAdditionalSecret("new_secret")
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	body := "{'data': 'foobar'}"
+
+	for _, secret := range []string{"old_secret", "new_secret"} {
+		header := mockWebhookHeader(time.Now(), secret, body)
+		if _, err := rotating.ValidatePayload(header, body); err != nil {
+			t.Errorf("expected no error for %s, but got %v", secret, err)
+		}
+	}
+
+	header := mockWebhookHeader(time.Now(), "new_secret", body)
+	if _, err := client.ValidatePayload(header, body); err != webhooks.ErrNoValidSignature {
+		t.Errorf("expected the original client to be unchanged, but got %v", err)
+	}
+}
+
+func TestWebhookWithEmptyAdditionalSecret(t *testing.T) {
+	client := webhooks.NewClient("old_secret")
+
+	if _, err := client.WithAdditionalSecret(""); err != webhooks.ErrMissingSecret {
+		t.Fatalf("expected a '%s' error, but got a '%v'", webhooks.ErrMissingSecret, err)
+	}
+
+	body := "{'data': 'foobar'}"
+	header := mockWebhookHeader(time.Now(), "", body)
+	if _, err := client.ValidatePayload(header, body); err != webhooks.ErrNoValidSignature {
+		t.Errorf("expected a '%s' error, but got a '%v'", webhooks.ErrNoValidSignature, err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	os.Unsetenv(webhooks.SecretEnvVar)
+
+	_, err := webhooks.NewClientFromEnv()
+	if err != webhooks.ErrMissingSecret {
+		t.Errorf("expected a '%s' error, but got a '%s'", webhooks.ErrMissingSecret, err)
+	}
+
+	secret := "secret"
+	os.Setenv(webhooks.SecretEnvVar, secret)
+	defer os.Unsetenv(webhooks.SecretEnvVar)
+
+	client, err := webhooks.NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	body := "{'data': 'foobar'}"
+	header := mockWebhookHeader(time.Now(), secret, body)
+
+	if _, err := client.ValidatePayload(header, body); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+}
+
+type contextKey struct{}
+
+func TestHandler(t *testing.T) {
+	secret := "secret"
+	client := webhooks.NewClient(secret)
+
+	body := `{"id":"event_123","event":"user.created","data":{"id":"user_123"}}`
+
+	var received events.Event
+	var value interface{}
+	handler := client.Handler(func(ctx context.Context, event events.Event) error {
+		received = event
+		value = ctx.Value(contextKey{})
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "request-value")
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set(webhooks.SignatureHeader, mockWebhookHeader(time.Now(), secret, body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected a %d status, but got %d", http.StatusOK, rec.Code)
+	}
+	if value != "request-value" {
+		t.Errorf("expected next to receive the request context, but got value %v", value)
+	}
+	if received.ID != "event_123" || received.Event != "user.created" {
+		t.Errorf("expected next to receive the parsed event, but got %+v", received)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	sampleEvent := []byte(`{"id":"event_123","event":"user.created","data":{"id":"user_123"}}`)
+
+	handler := webhooks.NewClient("secret").Handler(func(ctx context.Context, event events.Event) error {
+		return nil
+	})
+
+	if err := webhooks.SelfTest(handler, "secret", sampleEvent); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+
+	if err := webhooks.SelfTest(handler, "other_secret", sampleEvent); err == nil {
+		t.Errorf("expected an error for a mismatching secret, but got none")
+	}
+}
+
+func TestHandlerWithInvalidSignature(t *testing.T) {
+	client := webhooks.NewClient("secret")
+
+	called := false
+	handler := client.Handler(func(ctx context.Context, event events.Event) error {
+		called = true
+		return nil
+	})
+
+	body := `{"id":"event_123","event":"user.created","data":{}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(webhooks.SignatureHeader, mockWebhookHeader(time.Now(), "other_secret", body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a %d status, but got %d", http.StatusBadRequest, rec.Code)
+	}
+	if called {
+		t.Errorf("expected next not to be called")
+	}
+}
+
+func TestHandlerWithOversizedBody(t *testing.T) {
+	secret := "secret"
+	client := webhooks.NewClient(secret)
+	client.SetMaxBodyBytes(64)
+
+	called := false
+	handler := client.Handler(func(ctx context.Context, event events.Event) error {
+		called = true
+		return nil
+	})
+
+	body := `{"id":"event_123","event":"user.created","data":{"id":"` + strings.Repeat("a", 64) + `"}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(webhooks.SignatureHeader, mockWebhookHeader(time.Now(), secret, body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected a %d status, but got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+	if called {
+		t.Errorf("expected next not to be called")
+	}
+
+	// A body of exactly the maximum size is accepted.
+	body = `{"id":"event_123","event":"user.created","data":{}}`
+	client.SetMaxBodyBytes(int64(len(body)))
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(webhooks.SignatureHeader, mockWebhookHeader(time.Now(), secret, body))
+	rec = httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected a %d status, but got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestRawBodyFromRequest(t *testing.T) {
+	client := webhooks.NewClient("secret")
+
+	body := `{"id":"event_123","event":"user.created","data":{"id":"user_123"}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(webhooks.SignatureHeader, mockWebhookHeader(time.Now(), "secret", body))
+
+	raw, err := webhooks.RawBodyFromRequest(req)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if string(raw) != body {
+		t.Errorf("expected raw body to be '%s', but got '%s'", body, raw)
+	}
+
+	if _, err = client.ValidatePayload(req.Header.Get(webhooks.SignatureHeader), string(raw)); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+
+	var event events.Event
+	if err = json.NewDecoder(req.Body).Decode(&event); err != nil {
+		t.Fatalf("expected the body to be readable again, but got %v", err)
+	}
+	if event.ID != "event_123" {
+		t.Errorf("expected event ID to be 'event_123', but got '%s'", event.ID)
+	}
+}
+
+func TestVerifyFromHeaderAndBody(t *testing.T) {
+	secret := "secret"
+	body := `{"id":"event_123","event":"connection.activated","data":{"foo":"bar"},"created_at":"2021-06-25T19:07:33.155Z"}`
+	now := time.Now()
+
+	tests := []struct {
+		scenario string
+		header   string
+		body     string
+		secret   string
+		err      error
+	}{
+		{
+			scenario: "Missing secret",
+			header:   mockWebhookHeader(now, secret, body),
+			body:     body,
+			err:      webhooks.ErrMissingSecret,
+		},
+		{
+			scenario: "Missing header",
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrNotSigned,
+		},
+		{
+			scenario: "Malformed header",
+			header:   "t=1,x",
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrInvalidHeader,
+		},
+		{
+			scenario: "Empty signature",
+			header:   "t=1, v1=",
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrNoValidSignature,
+		},
+		{
+			scenario: "Invalid timestamp",
+			header:   "t=yesterday, v1=abc",
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrInvalidHeader,
+		},
+		{
+			scenario: "Timestamp outside tolerance",
+			header:   mockWebhookHeader(now.Add(-5*time.Minute), secret, body),
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrInvalidTimestamp,
+		},
+		{
+			scenario: "Timestamp in the future",
+			header:   mockWebhookHeader(now.Add(5*time.Minute), secret, body),
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrOutsideTolerance,
+		},
+		{
+			scenario: "Signed with another secret",
+			header:   mockWebhookHeader(now, "other_secret", body),
+			body:     body,
+			secret:   secret,
+			err:      webhooks.ErrNoValidSignature,
+		},
+		{
+			scenario: "Tampered body",
+			header:   mockWebhookHeader(now, secret, body),
+			body:     strings.Replace(body, "bar", "baz", -1),
+			secret:   secret,
+			err:      webhooks.ErrNoValidSignature,
+		},
+		{
+			scenario: "Body that is not an event",
+			header:   mockWebhookHeader(now, secret, "not json"),
+			body:     "not json",
+			secret:   secret,
+			err:      webhooks.ErrInvalidBody,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			_, err := webhooks.VerifyFromHeaderAndBody(test.header, []byte(test.body), test.secret, 3*time.Minute)
+			if err != test.err {
+				t.Errorf("expected a '%v' error, but got a '%v'", test.err, err)
+			}
+		})
+	}
+
+	t.Run("Valid webhook", func(t *testing.T) {
+		header := mockWebhookHeader(now, secret, body)
+
+		event, err := webhooks.VerifyFromHeaderAndBody(header, []byte(body), secret, 3*time.Minute)
+		if err != nil {
+			t.Fatalf("expected no error, but got %v", err)
+		}
+		if event.ID != "event_123" || event.Event != "connection.activated" {
+			t.Errorf("expected event 'event_123' of type 'connection.activated', but got %+v", event)
+		}
+		if string(event.Data) != `{"foo":"bar"}` {
+			t.Errorf("expected data to be '{\"foo\":\"bar\"}', but got '%s'", event.Data)
+		}
+	})
+}
+
+func TestRawBodyFromRequestWithOversizedBody(t *testing.T) {
+	body := strings.Repeat("a", int(webhooks.DefaultMaxBodyBytes)+1)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+
+	if _, err := webhooks.RawBodyFromRequest(req); err != webhooks.ErrBodyTooLarge {
+		t.Errorf("expected a '%s' error, but got a '%v'", webhooks.ErrBodyTooLarge, err)
+	}
+
+	body = strings.Repeat("a", int(webhooks.DefaultMaxBodyBytes))
+	req = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+
+	raw, err := webhooks.RawBodyFromRequest(req)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if len(raw) != len(body) {
+		t.Errorf("expected a body of %d bytes, but got %d", len(body), len(raw))
+	}
+}
+
 func mockWebhookHeader(now time.Time, secret string, body string) string {
-	stringTime := strconv.FormatInt(now.Round(0).Unix()*1000, 10)
+	stringTime := strconv.FormatInt(now.Round(0).UnixNano()/int64(time.Millisecond), 10)
 	signedBody := stringTime + "." + body
 	convertedSecret := hmac.New(sha256.New, []byte(secret))
 	convertedSecret.Write([]byte(signedBody))
@@ -9,3 +9,7 @@ func IsBadRequest(err error) bool {
 	var httpError HTTPError
 	return errors.As(err, &httpError) && httpError.Code == http.StatusBadRequest
 }
+
+// ErrResponseTooLarge is returned when reading a response body larger than the
+// MaxResponseBytes of the client.
+var ErrResponseTooLarge = errors.New("response body exceeds the maximum size")
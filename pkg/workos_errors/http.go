@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // TryGetHTTPError returns an error when the http response contains invalid
@@ -25,7 +27,7 @@ func TryGetHTTPError(r *http.Response) error {
 	} else if isJsonResponse(r) {
 		msg, code, errors, fieldErrors = getJsonErrorMessage(body, r.StatusCode)
 	} else {
-		msg = string(body)
+		msg = truncateBody(body)
 	}
 
 	return HTTPError{
@@ -36,9 +38,45 @@ func TryGetHTTPError(r *http.Response) error {
 		ErrorCode:   code,
 		Errors:      errors,
 		FieldErrors: fieldErrors,
+		RetryAfter:  getRetryAfter(r, body),
 	}
 }
 
+// getRetryAfter returns the delay after which the request can be retried, read
+// from the Retry-After header or from the retry_after field of a JSON body, in
+// seconds.
+func getRetryAfter(r *http.Response, body []byte) time.Duration {
+	if seconds, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if !isJsonResponse(r) {
+		return 0
+	}
+
+	var payload struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.RetryAfter <= 0 {
+		return 0
+	}
+	return time.Duration(payload.RetryAfter * float64(time.Second))
+}
+
+// maxBodySnippetLength is the maximum length of the raw body used as message
+// for errors whose body could not be decoded.
+const maxBodySnippetLength = 512
+
+// truncateBody returns the raw body as a string, truncated to a snippet so that
+// large non-JSON bodies (eg. an HTML page returned by a gateway) don't flood
+// the error message.
+func truncateBody(b []byte) string {
+	if len(b) <= maxBodySnippetLength {
+		return string(b)
+	}
+	return string(b[:maxBodySnippetLength]) + "..."
+}
+
 func isJsonResponse(r *http.Response) bool {
 	return strings.Contains(r.Header.Get("Content-Type"), "application/json")
 }
@@ -54,7 +92,7 @@ func getJsonErrorMessage(b []byte, statusCode int) (string, string, []string, []
 		}
 
 		if err := json.Unmarshal(b, &unprocesableEntityPayload); err != nil {
-			return string(b), "", nil, nil
+			return truncateBody(b), "", nil, nil
 		}
 
 		return unprocesableEntityPayload.Message, unprocesableEntityPayload.Code, nil, unprocesableEntityPayload.FieldErrors
@@ -69,13 +107,13 @@ func getJsonErrorMessage(b []byte, statusCode int) (string, string, []string, []
 	}
 
 	if err := json.Unmarshal(b, &payload); err != nil {
-		return string(b), "", nil, nil
+		return truncateBody(b), "", nil, nil
 	}
 
 	if payload.Error != "" && payload.ErrorDescription != "" {
 		return fmt.Sprintf("%s %s", payload.Error, payload.ErrorDescription), "", nil, nil
 	} else if payload.Message != "" && len(payload.Errors) == 0 {
-		return payload.Message, "", nil, nil
+		return payload.Message, payload.Code, nil, nil
 	} else if payload.Message != "" && len(payload.Errors) > 0 {
 		return payload.Message, payload.Code, payload.Errors, nil
 	}
@@ -92,6 +130,10 @@ type HTTPError struct {
 	ErrorCode   string
 	Errors      []string
 	FieldErrors []FieldError
+
+	// The delay after which the request can be retried, when the API provides
+	// one, eg. for rate limited requests.
+	RetryAfter time.Duration
 }
 
 type FieldError struct {
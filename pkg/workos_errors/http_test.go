@@ -3,7 +3,9 @@ package workos_errors
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -124,6 +126,29 @@ func TestGetHTTPErrorWithTextPayload(t *testing.T) {
 	t.Log(httperr)
 }
 
+func TestGetHTTPErrorWithHTMLPayload(t *testing.T) {
+	page := "<html><head><title>502 Bad Gateway</title></head><body>" +
+		strings.Repeat("<p>upstream unavailable</p>", 50) +
+		"</body></html>"
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-ID", "GOrOXx")
+	rec.Header().Set("Content-Type", "text/html")
+	rec.WriteHeader(http.StatusBadGateway)
+	rec.WriteString(page)
+
+	err := TryGetHTTPError(rec.Result())
+	require.Error(t, err)
+
+	httperr := err.(HTTPError)
+	require.Equal(t, http.StatusBadGateway, httperr.Code)
+	require.Equal(t, "502 Bad Gateway", httperr.Status)
+	require.Equal(t, "GOrOXx", httperr.RequestID)
+	require.Equal(t, page[:maxBodySnippetLength]+"...", httperr.Message)
+
+	t.Log(httperr)
+}
+
 func TestGetHTTPErrorWithoutRequestID(t *testing.T) {
 	rec := httptest.NewRecorder()
 	rec.Header().Set("Content-Type", "application/json")
@@ -161,6 +186,37 @@ func TestGetHTTPErrorWithoutErrorOrErrorDescription(t *testing.T) {
 	t.Log(httperr)
 }
 
+func TestGetHTTPErrorWithRetryAfter(t *testing.T) {
+	t.Run("Retry-After header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Retry-After", "30")
+		rec.WriteHeader(http.StatusTooManyRequests)
+
+		httperr := TryGetHTTPError(rec.Result()).(HTTPError)
+		require.Equal(t, 30*time.Second, httperr.RetryAfter)
+	})
+
+	t.Run("retry_after field", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", "application/json")
+		rec.WriteHeader(http.StatusBadRequest)
+		rec.WriteString(`{"message":"Too many attempts", "code": "authentication_rate_limit", "retry_after": 60}`)
+
+		httperr := TryGetHTTPError(rec.Result()).(HTTPError)
+		require.Equal(t, "Too many attempts", httperr.Message)
+		require.Equal(t, "authentication_rate_limit", httperr.ErrorCode)
+		require.Equal(t, time.Minute, httperr.RetryAfter)
+	})
+
+	t.Run("No delay", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusBadRequest)
+
+		httperr := TryGetHTTPError(rec.Result()).(HTTPError)
+		require.Zero(t, httperr.RetryAfter)
+	})
+}
+
 func TestGetHTTPErrorNoError(t *testing.T) {
 	rec := httptest.NewRecorder()
 	rec.Header().Set("X-Request-ID", "GOrOXx")